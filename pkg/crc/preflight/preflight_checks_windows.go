@@ -1,9 +1,13 @@
 package preflight
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/crc-org/crc/pkg/crc/logging"
 
@@ -12,13 +16,105 @@ import (
 
 	"github.com/crc-org/crc/pkg/crc/constants"
 	"github.com/crc-org/crc/pkg/crc/machine/hyperv"
+	winhyperv "github.com/crc-org/crc/pkg/os/windows/hyperv"
+	"github.com/crc-org/crc/pkg/os/windows/winrm"
 )
 
 const (
 	// Fall Creators update comes with the "Default Switch"
 	minimumWindowsReleaseID = 1709
+
+	// defaultCheckTimeout bounds how long a single Hyper-V preflight check
+	// waits on powershell.Execute before giving up, so a wedged VMMS or a
+	// missing WMI provider doesn't freeze `crc setup` indefinitely.
+	defaultCheckTimeout = 8 * time.Second
+)
+
+// hyperVDriverKind selects between the native WMI/HCS driver and the
+// original PowerShell-based one. It defaults to the PowerShell driver and
+// is switched by SetHyperVDriverKind, or at init by setting the
+// CRC_HYPERV_DRIVER environment variable to "native".
+var hyperVDriverKind = winhyperv.PowerShell
+
+// SetHyperVDriverKind changes which Hyper-V driver subsequent preflight
+// checks use. It is meant to be called once, at startup, from the
+// `hyperv-driver` config property handler.
+func SetHyperVDriverKind(kind winhyperv.Kind) {
+	hyperVDriverKind = kind
+}
+
+func init() {
+	// Until the `hyperv-driver` config property is wired to call
+	// SetHyperVDriverKind, allow opting into the native driver via an
+	// environment variable, so it can still be exercised/rolled out.
+	if strings.EqualFold(os.Getenv("CRC_HYPERV_DRIVER"), string(winhyperv.Native)) {
+		hyperVDriverKind = winhyperv.Native
+	}
+}
+
+func selectedHyperVDriver() winhyperv.Driver {
+	return winhyperv.New(hyperVDriverKind)
+}
+
+// parentHostFQDNOrIP, parentHostUser, parentHostPassword and
+// parentHostVMName identify the Hyper-V host crc's VM is nested under, and
+// the name that VM is registered under *on that host* (which is not the
+// same as this guest's own hostname). They are read at init from the
+// CRC_HYPERV_PARENT_* environment variables and can be replaced by
+// SetParentHostConfig, and are only needed when checkNestedVirtualizationCtx
+// finds that virtualization extensions are not already exposed to the
+// current VM.
+var (
+	parentHostFQDNOrIP string
+	parentHostUser     string
+	parentHostPassword string
+	parentHostVMName   string
 )
 
+func init() {
+	// Until the `nested-virtualization` config properties are wired to
+	// call SetParentHostConfig, allow providing them via environment
+	// variables, so the fix path can still be exercised/rolled out.
+	parentHostFQDNOrIP = os.Getenv("CRC_HYPERV_PARENT_HOST")
+	parentHostUser = os.Getenv("CRC_HYPERV_PARENT_USER")
+	parentHostPassword = os.Getenv("CRC_HYPERV_PARENT_PASSWORD")
+	parentHostVMName = os.Getenv("CRC_HYPERV_PARENT_VM_NAME")
+}
+
+// SetParentHostConfig records how to reach, and which VM to act on, on the
+// Hyper-V host this guest is nested under. It is meant to be called once,
+// at startup, from the `nested-virtualization` config property handlers.
+func SetParentHostConfig(fqdnOrIP, user, password, vmName string) {
+	parentHostFQDNOrIP = fqdnOrIP
+	parentHostUser = user
+	parentHostPassword = password
+	parentHostVMName = vmName
+}
+
+func parentHostConfigured() bool {
+	return parentHostFQDNOrIP != "" && parentHostUser != "" && parentHostVMName != ""
+}
+
+// exposeVirtualizationExtensionsCmdFor and enableMacAddressSpoofingCmdFor
+// are the two commands a Hyper-V host admin must run, against the *parent*
+// host, to let a nested VM expose virtualization extensions to its own
+// guests. fixNestedVirtualization runs them itself over WinRM when a
+// parent host is configured, and otherwise prints them for the admin.
+func exposeVirtualizationExtensionsCmdFor(vmName string) string {
+	return fmt.Sprintf(`Set-VMProcessor -VMName "%s" -ExposeVirtualizationExtensions $true`, vmName)
+}
+
+func enableMacAddressSpoofingCmdFor(vmName string) string {
+	return fmt.Sprintf(`Get-VMNetworkAdapter -VMName "%s" | Set-VMNetworkAdapter -MacAddressSpoofing On`, vmName)
+}
+
+func asVmmsTimeoutError(err error, checkDescription string) error {
+	if errors.Is(err, powershell.ErrTimeout) {
+		return fmt.Errorf("%s timed out - is the Hyper-V Virtual Machine Management service (VMMS) responsive? %v", checkDescription, err)
+	}
+	return err
+}
+
 func checkVersionOfWindowsUpdate() error {
 	windowsReleaseID := `(Get-ItemProperty -Path "HKLM:\SOFTWARE\Microsoft\Windows NT\CurrentVersion" -Name ReleaseId).ReleaseId`
 
@@ -59,43 +155,109 @@ func checkWindowsEdition() error {
 	return nil
 }
 
-func checkHyperVInstalled() error {
-	// check to see if a hypervisor is present. if hyper-v is installed and enabled,
-	checkHypervisorPresent := `@(Get-Wmiobject Win32_ComputerSystem).HypervisorPresent`
-	stdOut, _, err := powershell.Execute(checkHypervisorPresent)
-	if err != nil {
+// checkWithTimeout binds fn to a context bounded by timeout, matching the
+// same duration recorded in that check's Check.timeout field - so the two
+// can't drift apart the way a second hardcoded constant inside fn would.
+func checkWithTimeout(timeout time.Duration, fn func(ctx context.Context) error) func() error {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return fn(ctx)
+	}
+}
+
+func checkHyperVInstalledCtx(ctx context.Context) error {
+	if err := selectedHyperVDriver().Installed(ctx); err != nil {
 		logging.Debug(err.Error())
-		return fmt.Errorf("Failed checking if Hyper-V is installed")
+		return asVmmsTimeoutError(err, "Checking if Hyper-V is installed")
 	}
-	if !strings.Contains(stdOut, "True") {
-		return fmt.Errorf("Hyper-V not installed")
+	return nil
+}
+
+func checkHyperVServiceRunningCtx(ctx context.Context) error {
+	if err := selectedHyperVDriver().ServiceRunning(ctx); err != nil {
+		logging.Debug(err.Error())
+		return asVmmsTimeoutError(err, "Checking if Hyper-V service is running")
 	}
+	return nil
+}
 
-	checkVmmsExists := `@(Get-Service vmms).Status`
-	_, stdErr, err := powershell.Execute(checkVmmsExists)
+// isRunningInsideVM reports whether the current machine is itself a VM, by
+// checking the computer's model string against the common names reported by
+// Hyper-V, VMware and KVM for virtual hardware.
+func isRunningInsideVM() (bool, error) {
+	stdout, _, err := powershell.Execute(`(Get-CimInstance Win32_ComputerSystem).Model`)
 	if err != nil {
-		logging.Debug(err.Error())
-		return fmt.Errorf("Failed checking if Hyper-V management service exists")
+		return false, fmt.Errorf("could not determine computer model: %w", err)
 	}
-	if strings.Contains(stdErr, "Get-Service") {
-		return fmt.Errorf("Hyper-V management service not available")
+	model := strings.ToLower(strings.TrimSpace(stdout))
+	for _, vmModel := range []string{"virtual machine", "vmware", "kvm"} {
+		if strings.Contains(model, vmModel) {
+			return true, nil
+		}
 	}
-
-	return nil
+	return false, nil
 }
 
-func checkHyperVServiceRunning() error {
-	// Check if Hyper-V's Virtual Machine Management Service is running
-	checkVmmsRunning := `@(Get-Service vmms).Status`
-	stdOut, _, err := powershell.Execute(checkVmmsRunning)
+// checkNestedVirtualizationCtx makes sure that, when crc is itself running
+// inside a Hyper-V (or other) VM, that VM was configured to expose
+// virtualization extensions to its own guests. Without this, crc's VM
+// starts but its networking silently breaks.
+func checkNestedVirtualizationCtx(ctx context.Context) error {
+	insideVM, err := isRunningInsideVM()
 	if err != nil {
 		logging.Debug(err.Error())
-		return fmt.Errorf("Failed checking if Hyper-V is running")
+		return err
 	}
-	if strings.TrimSpace(stdOut) != "Running" {
-		return fmt.Errorf("Hyper-V Virtual Machine Management service not running")
+	if !insideVM {
+		// Not running nested, nothing to check.
+		return nil
+	}
+
+	stdout, _, err := powershell.ExecuteContext(ctx, `@(Get-CimInstance Win32_ComputerSystem).HypervisorPresent`)
+	if err != nil {
+		return asVmmsTimeoutError(err, "Checking if nested virtualization is enabled")
 	}
+	if strings.Contains(stdout, "True") {
+		return nil
+	}
+
+	return fmt.Errorf("nested virtualization is not enabled for this VM")
+}
 
+// fixNestedVirtualization enables virtualization extensions and MAC address
+// spoofing on this VM's vNIC, by running the required commands against the
+// Hyper-V parent host over WinRM. It requires the parent host's address and
+// credentials to be configured; otherwise it tells the user which two
+// commands the host admin must run themselves.
+func fixNestedVirtualization() error {
+	if !parentHostConfigured() {
+		// We have no way to know the VM's name as registered on the parent
+		// host - that's a property of the parent's Hyper-V inventory, not
+		// of this guest - so fall back to this guest's own hostname as a
+		// best-effort hint and tell the admin to double check it.
+		hostname, _ := os.Hostname()
+		exposeCmd := exposeVirtualizationExtensionsCmdFor(hostname)
+		spoofCmd := enableMacAddressSpoofingCmdFor(hostname)
+		return fmt.Errorf(
+			"nested virtualization is not enabled for this VM, and no parent Hyper-V host is configured to fix it automatically\n"+
+				"ask the administrator of the Hyper-V host running this VM to run, from that host (replacing %q with this VM's actual name if different):\n  %s\n  %s",
+			hostname, exposeCmd, spoofCmd)
+	}
+
+	exposeCmd := exposeVirtualizationExtensionsCmdFor(parentHostVMName)
+	spoofCmd := enableMacAddressSpoofingCmdFor(parentHostVMName)
+
+	host := winrm.HostConfig{FQDNOrIP: parentHostFQDNOrIP, User: parentHostUser, Password: parentHostPassword}
+	if _, err := winrm.Run(host, exposeCmd); err != nil {
+		return fmt.Errorf(
+			"could not reach the Hyper-V host %s to enable nested virtualization: %w\n"+
+				"ask the host administrator to run, from that host:\n  %s\n  %s",
+			parentHostFQDNOrIP, err, exposeCmd, spoofCmd)
+	}
+	if _, err := winrm.Run(host, spoofCmd); err != nil {
+		return fmt.Errorf("could not enable MAC address spoofing on the Hyper-V host %s: %w", parentHostFQDNOrIP, err)
+	}
 	return nil
 }
 
@@ -130,6 +292,15 @@ func checkIfHyperVVirtualSwitchExists() error {
 		return nil
 	}
 
+	// fall back to asking the Hyper-V driver directly, in case winnet's
+	// view of the switches is stale
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+	defer cancel()
+	if foundName, err := selectedHyperVDriver().VirtualSwitchExists(ctx); err == nil {
+		logging.Info("Found Virtual Switch to use: ", foundName)
+		return nil
+	}
+
 	return fmt.Errorf("Virtual Switch not found")
 }
 
@@ -152,19 +323,11 @@ func removeDNSServerAddress() error {
 	return nil
 }
 
-func removeCrcVM() (err error) {
-	if _, _, err := powershell.Execute("Get-VM -Name crc"); err != nil {
-		// This means that there is no crc VM exist
-		return nil
-	}
-	stopVMCommand := fmt.Sprintf(`Stop-VM -Name "%s" -Force`, constants.DefaultName)
-	if _, _, err := powershell.Execute(stopVMCommand); err != nil {
-		// ignore the error as this is useless (prefer not to use nolint here)
-		return err
-	}
-	removeVMCommand := fmt.Sprintf(`Remove-VM -Name "%s" -Force`, constants.DefaultName)
-	if _, _, err := powershell.Execute(removeVMCommand); err != nil {
-		// ignore the error as this is useless (prefer not to use nolint here)
+func removeCrcVM() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+	defer cancel()
+
+	if err := selectedHyperVDriver().RemoveVM(ctx, constants.DefaultName); err != nil {
 		return err
 	}
 	logging.Debug("'crc' VM is removed")
@@ -172,9 +335,12 @@ func removeCrcVM() (err error) {
 }
 
 func checkIfAdminHelperServiceRunning() error {
-	stdout, stderr, err := powershell.Execute(fmt.Sprintf("(Get-Service %s).Status", constants.AdminHelperServiceName))
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+	defer cancel()
+
+	stdout, stderr, err := powershell.ExecuteContext(ctx, fmt.Sprintf("(Get-Service %s).Status", constants.AdminHelperServiceName))
 	if err != nil {
-		return fmt.Errorf("%s service is not present %v: %s", constants.AdminHelperServiceName, err, stderr)
+		return asVmmsTimeoutError(fmt.Errorf("%s service is not present %w: %s", constants.AdminHelperServiceName, err, stderr), "Checking if admin-helper service is running")
 	}
 	if strings.TrimSpace(stdout) != "Running" {
 		return fmt.Errorf("%s service is not running", constants.AdminHelperServiceName)
@@ -1,6 +1,7 @@
 package preflight
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -11,6 +12,16 @@ import (
 	"github.com/code-ready/crc/pkg/os/windows/win32"
 )
 
+// Per-check timeouts, also recorded on the matching Check.timeout field so
+// the two can never drift apart: checkWithTimeout and the field literal
+// below both read the same variable.
+var (
+	hyperVInstalledTimeout      = defaultCheckTimeout
+	nestedVirtualizationTimeout = defaultCheckTimeout
+	hyperVServiceRunningTimeout = defaultCheckTimeout
+	vsockTimeout                = defaultCheckTimeout
+)
+
 var hypervPreflightChecks = []Check{
 	{
 		configKeySuffix:  "check-administrator-user",
@@ -18,6 +29,8 @@ var hypervPreflightChecks = []Check{
 		check:            checkIfRunningAsNormalUser,
 		fixDescription:   "crc should be ran in a shell without administrator rights",
 		flags:            NoFix | StartUpOnly,
+		category:         categoryUser,
+		severity:         severityBlocking,
 
 		labels: labels{Os: Windows},
 	},
@@ -27,6 +40,8 @@ var hypervPreflightChecks = []Check{
 		check:            checkVersionOfWindowsUpdate,
 		fixDescription:   "Please manually update your Windows 10 installation",
 		flags:            NoFix | StartUpOnly,
+		category:         categoryUser,
+		severity:         severityBlocking,
 
 		labels: labels{Os: Windows},
 	},
@@ -36,16 +51,34 @@ var hypervPreflightChecks = []Check{
 		check:            checkWindowsEdition,
 		fixDescription:   "Your Windows edition is not supported. Consider using Professional or Enterprise editions of Windows",
 		flags:            NoFix | StartUpOnly,
+		category:         categoryUser,
+		severity:         severityBlocking,
 
 		labels: labels{Os: Windows},
 	},
 	{
 		configKeySuffix:  "check-hyperv-installed",
 		checkDescription: "Checking if Hyper-V is installed and operational",
-		check:            checkHyperVInstalled,
+		check:            checkWithTimeout(hyperVInstalledTimeout, checkHyperVInstalledCtx),
 		fixDescription:   "Installing Hyper-V",
 		fix:              fixHyperVInstalled,
 		flags:            StartUpOnly,
+		timeout:          hyperVInstalledTimeout,
+		category:         categoryHyperV,
+		severity:         severityBlocking,
+
+		labels: labels{Os: Windows},
+	},
+	{
+		configKeySuffix:  "check-nested-virtualization",
+		checkDescription: "Checking if nested virtualization is enabled",
+		check:            checkWithTimeout(nestedVirtualizationTimeout, checkNestedVirtualizationCtx),
+		fixDescription:   "Enabling nested virtualization",
+		fix:              fixNestedVirtualization,
+		flags:            StartUpOnly,
+		timeout:          nestedVirtualizationTimeout,
+		category:         categoryHyperV,
+		severity:         severityWarning,
 
 		labels: labels{Os: Windows},
 	},
@@ -72,8 +105,10 @@ var hypervPreflightChecks = []Check{
 			}
 			return nil
 		},
-		flags:  NoFix | StartUpOnly,
-		labels: labels{Os: Windows},
+		flags:    NoFix | StartUpOnly,
+		category: categoryUser,
+		severity: severityBlocking,
+		labels:   labels{Os: Windows},
 	},
 	{
 		configKeySuffix:  "check-user-in-hyperv-group",
@@ -81,16 +116,21 @@ var hypervPreflightChecks = []Check{
 		check:            checkIfUserPartOfHyperVAdmins,
 		fixDescription:   "Adding current user to Hyper-V Admins group",
 		fix:              fixUserPartOfHyperVAdmins,
+		category:         categoryUser,
+		severity:         severityBlocking,
 
 		labels: labels{Os: Windows},
 	},
 	{
 		configKeySuffix:  "check-hyperv-service-running",
 		checkDescription: "Checking if Hyper-V service is enabled",
-		check:            checkHyperVServiceRunning,
+		check:            checkWithTimeout(hyperVServiceRunningTimeout, checkHyperVServiceRunningCtx),
 		fixDescription:   "Enabling Hyper-V service",
 		fix:              fixHyperVServiceRunning,
 		flags:            StartUpOnly,
+		timeout:          hyperVServiceRunningTimeout,
+		category:         categoryHyperV,
+		severity:         severityBlocking,
 
 		labels: labels{Os: Windows},
 	},
@@ -100,6 +140,8 @@ var hypervPreflightChecks = []Check{
 		check:            checkIfHyperVVirtualSwitchExists,
 		fixDescription:   "Unable to perform Hyper-V administrative commands. Please reboot your system and run 'crc setup' to complete the setup process",
 		flags:            NoFix | StartUpOnly,
+		category:         categoryHyperV,
+		severity:         severityBlocking,
 
 		labels: labels{Os: Windows},
 	},
@@ -107,6 +149,8 @@ var hypervPreflightChecks = []Check{
 		cleanupDescription: "Removing dns server from interface",
 		cleanup:            removeDNSServerAddress,
 		flags:              CleanUpOnly,
+		category:           categoryNetwork,
+		severity:           severityWarning,
 
 		labels: labels{Os: Windows},
 	},
@@ -114,6 +158,8 @@ var hypervPreflightChecks = []Check{
 		cleanupDescription: "Removing the crc VM if exists",
 		cleanup:            removeCrcVM,
 		flags:              CleanUpOnly,
+		category:           categoryHyperV,
+		severity:           severityWarning,
 
 		labels: labels{Os: Windows},
 	},
@@ -123,12 +169,15 @@ var vsockChecks = []Check{
 	{
 		configKeySuffix:    "check-vsock",
 		checkDescription:   "Checking if vsock is correctly configured",
-		check:              checkVsock,
+		check:              checkWithTimeout(vsockTimeout, checkVsockCtx),
 		fixDescription:     "Checking if vsock is correctly configured",
 		fix:                fixVsock,
 		cleanupDescription: "Removing vsock service from hyperv registry",
 		cleanup:            cleanVsock,
 		flags:              NoFix | StartUpOnly,
+		timeout:            vsockTimeout,
+		category:           categoryVsock,
+		severity:           severityBlocking,
 
 		labels: labels{Os: Windows, NetworkMode: User},
 	},
@@ -151,9 +200,12 @@ const (
 	registryValue = "gvisor-tap-vsock"
 )
 
-func checkVsock() error {
-	stdout, _, err := powershell.Execute(fmt.Sprintf(`Get-Item -Path "%s\%s"`, registryDirectory, registryKey))
+func checkVsockCtx(ctx context.Context) error {
+	stdout, _, err := powershell.ExecuteContext(ctx, fmt.Sprintf(`Get-Item -Path "%s\%s"`, registryDirectory, registryKey))
 	if err != nil {
+		if errors.Is(err, powershell.ErrTimeout) {
+			return fmt.Errorf("checking if vsock is correctly configured timed out - is VMMS responsive? %w", err)
+		}
 		return err
 	}
 	if !strings.Contains(stdout, registryValue) {
@@ -162,6 +214,14 @@ func checkVsock() error {
 	return nil
 }
 
+// checkVsock is the no-context entry point used by cleanVsock, which isn't
+// itself timeout-configurable.
+func checkVsock() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+	defer cancel()
+	return checkVsockCtx(ctx)
+}
+
 func fixVsock() error {
 	cmds := []string{
 		fmt.Sprintf(`$service = New-Item -Path "%s" -Name "%s"`, registryDirectory, registryKey),
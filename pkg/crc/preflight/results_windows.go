@@ -0,0 +1,155 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// category groups related checks together (Hyper-V, vsock, user, network,
+// ...) so tooling wrapping crc can present them as sections instead of a
+// flat list.
+type category string
+
+const (
+	categoryHyperV  category = "hyperv"
+	categoryVsock   category = "vsock"
+	categoryUser    category = "user"
+	categoryNetwork category = "network"
+)
+
+// severity indicates how serious a failing check is. A blocking check means
+// `crc start` cannot proceed; a warning means crc will still work but
+// something is degraded (e.g. slower networking under nested virtualization).
+type severity string
+
+const (
+	severityBlocking severity = "blocking"
+	severityWarning  severity = "warning"
+)
+
+// Status is the outcome of running a single check.
+type Status string
+
+const (
+	StatusPass    Status = "pass"
+	StatusFail    Status = "fail"
+	StatusSkipped Status = "skipped"
+	StatusFixed   Status = "fixed"
+)
+
+// Result is the machine-readable outcome of running one preflight check. It
+// is the JSON-friendly counterpart of Check, meant for tools wrapping crc
+// (installers, IDE integrations, the Windows tray) that need to know which
+// check ran, what happened, and whether a reboot is now required, without
+// having to parse log lines.
+type Result struct {
+	ID             string   `json:"id"`
+	Category       category `json:"category"`
+	Severity       severity `json:"severity"`
+	Description    string   `json:"description"`
+	Status         Status   `json:"status"`
+	Error          string   `json:"error,omitempty"`
+	FixApplied     bool     `json:"fixApplied"`
+	RequiresReboot bool     `json:"requiresReboot"`
+	DurationMs     int64    `json:"durationMs"`
+}
+
+// runOne executes a single check, attempting its fix on failure unless the
+// check is marked NoFix, and turns the outcome into a Result.
+func runOne(c Check) Result {
+	result := Result{
+		ID:          c.configKeySuffix,
+		Category:    c.category,
+		Severity:    c.severity,
+		Description: c.checkDescription,
+	}
+
+	if c.check == nil {
+		result.Status = StatusSkipped
+		return result
+	}
+
+	start := time.Now()
+	err := c.check()
+	if err == nil {
+		result.Status = StatusPass
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	if errors.Is(err, errReboot) {
+		result.RequiresReboot = true
+	}
+
+	if c.flags&NoFix == 0 && c.fix != nil {
+		if fixErr := c.fix(); fixErr == nil {
+			result.Status = StatusFixed
+			result.FixApplied = true
+			result.DurationMs = time.Since(start).Milliseconds()
+			return result
+		} else if errors.Is(fixErr, errReboot) {
+			result.RequiresReboot = true
+			result.FixApplied = true
+		}
+	}
+
+	result.Status = StatusFail
+	result.Error = err.Error()
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
+
+// setupChecks returns the checks RunAllChecks/StreamChecksJSON run, in
+// registration order: every applicable check except the CleanUpOnly ones,
+// since both of those back `crc setup`, not `crc cleanup`. getAllPreflightChecks
+// already applies the Os/NetworkMode label filtering that getPreflightChecks
+// does for the rest of the preflight package; this only adds the flags-based
+// filtering that running the full Result-producing list still skipped.
+func setupChecks() []Check {
+	all := getAllPreflightChecks()
+	checks := make([]Check, 0, len(all))
+	for _, c := range all {
+		if c.flags&CleanUpOnly != 0 {
+			continue
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// RunAllChecks runs every check setupChecks selects and returns a Result for
+// each of them, in the order they were registered. It stops early if ctx is
+// done.
+func RunAllChecks(ctx context.Context) []Result {
+	checks := setupChecks()
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, runOne(c))
+	}
+	return results
+}
+
+// StreamChecksJSON runs every check setupChecks selects, writing each Result
+// to w as newline-delimited JSON as soon as it completes. This is meant to
+// back a `crc setup --output=json` CLI flag, letting a wrapping UI show
+// progress instead of waiting for every check to finish before it sees
+// anything - but this tree has no cmd/ package defining the crc CLI to add
+// that flag to, so for now it's reachable only as a library call.
+func StreamChecksJSON(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, c := range setupChecks() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := enc.Encode(runOne(c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package hyperv talks to the Windows Hyper-V hypervisor, either natively
+// through WMI/HCS or, as a fallback, by shelling out to PowerShell. Having
+// a single Driver interface lets the preflight checks and VM lifecycle
+// code stop caring which path is in use.
+package hyperv
+
+import "context"
+
+// Driver is implemented by each way of talking to Hyper-V.
+type Driver interface {
+	// Installed reports whether Hyper-V is installed and its management
+	// service is reachable.
+	Installed(ctx context.Context) error
+	// ServiceRunning reports whether the Virtual Machine Management
+	// Service (vmms) is running.
+	ServiceRunning(ctx context.Context) error
+	// VirtualSwitchExists reports whether a usable virtual switch exists,
+	// returning its name.
+	VirtualSwitchExists(ctx context.Context) (string, error)
+	// RemoveVM stops and deletes the named VM if it exists.
+	RemoveVM(ctx context.Context, name string) error
+}
+
+// Kind selects which Driver implementation to use.
+type Kind string
+
+const (
+	Native     Kind = "native"
+	PowerShell Kind = "powershell"
+)
+
+// New builds the Driver requested by kind. An empty or unrecognised kind
+// falls back to the PowerShell driver, which is the one this package has
+// always used.
+func New(kind Kind) Driver {
+	if kind == Native {
+		return newNativeDriver()
+	}
+	return &PowerShellDriver{}
+}
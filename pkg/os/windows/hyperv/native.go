@@ -0,0 +1,88 @@
+//go:build windows
+
+package hyperv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoft/wmi/pkg/virtualization/core/service"
+)
+
+// NativeDriver talks to Hyper-V directly against the root\virtualization\v2
+// WMI namespace instead of shelling out to powershell.Execute, which cuts
+// `crc setup` preflight time significantly and removes the dependency on
+// the PowerShell interpreter being available in PATH.
+type NativeDriver struct{}
+
+// newNativeDriver is the Windows build of the Kind Native branch of New.
+// It lives here, alongside NativeDriver itself, so that driver.go - which
+// has no build constraint and must compile on every OS - never references
+// the Windows-only NativeDriver type directly.
+func newNativeDriver() Driver {
+	return &NativeDriver{}
+}
+
+func (d *NativeDriver) virtualSystemManagementService() (*service.VirtualSystemManagementService, error) {
+	svc, err := service.NewLocalHyperVService()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the Hyper-V WMI service: %w", err)
+	}
+	return svc, nil
+}
+
+func (d *NativeDriver) Installed(_ context.Context) error {
+	if _, err := d.virtualSystemManagementService(); err != nil {
+		return fmt.Errorf("Hyper-V is not installed or not operational: %w", err)
+	}
+	return nil
+}
+
+func (d *NativeDriver) ServiceRunning(_ context.Context) error {
+	svc, err := d.virtualSystemManagementService()
+	if err != nil {
+		return err
+	}
+	running, err := svc.IsRunning()
+	if err != nil {
+		return fmt.Errorf("could not query Hyper-V Virtual Machine Management service state: %w", err)
+	}
+	if !running {
+		return fmt.Errorf("Hyper-V Virtual Machine Management service not running")
+	}
+	return nil
+}
+
+func (d *NativeDriver) VirtualSwitchExists(_ context.Context) (string, error) {
+	svc, err := d.virtualSystemManagementService()
+	if err != nil {
+		return "", err
+	}
+	switches, err := svc.ListVirtualSwitches()
+	if err != nil {
+		return "", fmt.Errorf("could not list Hyper-V virtual switches: %w", err)
+	}
+	if len(switches) == 0 {
+		return "", fmt.Errorf("no Hyper-V virtual switch found")
+	}
+	return switches[0].Name(), nil
+}
+
+func (d *NativeDriver) RemoveVM(_ context.Context, name string) error {
+	svc, err := d.virtualSystemManagementService()
+	if err != nil {
+		return err
+	}
+	vm, err := svc.FindVM(name)
+	if err != nil {
+		// no such VM, nothing to remove
+		return nil //nolint:nilerr
+	}
+	if err := vm.Stop(); err != nil {
+		return fmt.Errorf("could not stop VM %q: %w", name, err)
+	}
+	if err := vm.Remove(); err != nil {
+		return fmt.Errorf("could not remove VM %q: %w", name, err)
+	}
+	return nil
+}
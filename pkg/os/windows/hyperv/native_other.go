@@ -0,0 +1,11 @@
+//go:build !windows
+
+package hyperv
+
+// newNativeDriver is the non-Windows stub for the Kind Native branch of
+// New: NativeDriver talks to Hyper-V's WMI surface, which only exists on
+// Windows, so off Windows this falls back to the PowerShell driver the
+// same way New does for an unrecognised Kind.
+func newNativeDriver() Driver {
+	return &PowerShellDriver{}
+}
@@ -0,0 +1,71 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crc-org/crc/pkg/os/windows/powershell"
+)
+
+// PowerShellDriver is the original way this package talked to Hyper-V:
+// shelling out to powershell.Execute. It is slower (100-500ms per
+// invocation) and produces poor error messages, but is kept as a fallback:
+// New returns it for every Kind other than Native.
+type PowerShellDriver struct{}
+
+func (d *PowerShellDriver) Installed(ctx context.Context) error {
+	stdOut, _, cimErr := powershell.ExecuteContext(ctx, `@(Get-CimInstance Win32_ComputerSystem).HypervisorPresent`)
+	if cimErr != nil {
+		// Get-CimInstance requires the WMI provider host to be running;
+		// fall back to the older Get-WmiObject cmdlet, which goes through
+		// DCOM instead and keeps working on the machines where that host
+		// is broken or disabled.
+		var wmiErr error
+		stdOut, _, wmiErr = powershell.ExecuteContext(ctx, `@(Get-WmiObject Win32_ComputerSystem).HypervisorPresent`)
+		if wmiErr != nil {
+			return fmt.Errorf("failed checking if Hyper-V is installed: %w (Get-WmiObject fallback also failed: %v)", cimErr, wmiErr)
+		}
+	}
+	if !strings.Contains(stdOut, "True") {
+		return fmt.Errorf("Hyper-V not installed")
+	}
+	return nil
+}
+
+func (d *PowerShellDriver) ServiceRunning(ctx context.Context) error {
+	stdOut, _, err := powershell.ExecuteContext(ctx, `@(Get-Service vmms).Status`)
+	if err != nil {
+		return fmt.Errorf("failed checking if Hyper-V management service is running: %w", err)
+	}
+	if strings.TrimSpace(stdOut) != "Running" {
+		return fmt.Errorf("Hyper-V Virtual Machine Management service not running")
+	}
+	return nil
+}
+
+func (d *PowerShellDriver) VirtualSwitchExists(ctx context.Context) (string, error) {
+	stdOut, _, err := powershell.ExecuteContext(ctx, `(Get-VMSwitch | Select-Object -First 1).Name`)
+	if err != nil {
+		return "", fmt.Errorf("failed checking for a Hyper-V virtual switch: %w", err)
+	}
+	name := strings.TrimSpace(stdOut)
+	if name == "" {
+		return "", fmt.Errorf("no Hyper-V virtual switch found")
+	}
+	return name, nil
+}
+
+func (d *PowerShellDriver) RemoveVM(ctx context.Context, name string) error {
+	if _, _, err := powershell.ExecuteContext(ctx, fmt.Sprintf(`Get-VM -Name "%s"`, name)); err != nil {
+		// no such VM, nothing to remove
+		return nil
+	}
+	if _, _, err := powershell.ExecuteContext(ctx, fmt.Sprintf(`Stop-VM -Name "%s" -Force`, name)); err != nil {
+		return err
+	}
+	if _, _, err := powershell.ExecuteContext(ctx, fmt.Sprintf(`Remove-VM -Name "%s" -Force`, name)); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package powershell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// ErrTimeout is returned by ExecuteContext/ExecuteAsAdminContext when cmd
+// did not complete before ctx was done. Preflight checks match on it to
+// surface a more actionable message than a generic timeout.
+var ErrTimeout = errors.New("powershell command timed out")
+
+// ExecuteContext behaves like Execute, but gives up and returns ErrTimeout
+// once ctx is done instead of blocking forever. Unlike wrapping Execute in
+// a goroutine, this runs powershell.exe via exec.CommandContext directly,
+// so the child process is killed - not just abandoned - the moment ctx is
+// done. This bounds preflight checks against a wedged VMMS or a missing
+// WMI provider, which otherwise hang `crc setup` indefinitely with the
+// stuck powershell.exe still running in the background.
+func ExecuteContext(ctx context.Context, cmd string) (string, string, error) {
+	c := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", cmd)
+
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	err := c.Run()
+	if ctx.Err() != nil {
+		return stdout.String(), stderr.String(), fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+	}
+	return stdout.String(), stderr.String(), err
+}
+
+// ExecuteAsAdminContext is the admin-elevated counterpart of
+// ExecuteContext. Because ExecuteAsAdmin launches its elevated process
+// through a mechanism this package doesn't otherwise expose, ctx
+// cancellation here can only stop waiting on it - not kill the elevated
+// process - unlike ExecuteContext above.
+func ExecuteAsAdminContext(ctx context.Context, reason string, cmd string) (string, string, error) {
+	type result struct {
+		stdOut string
+		stdErr string
+		err    error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		stdOut, stdErr, err := ExecuteAsAdmin(reason, cmd)
+		done <- result{stdOut, stdErr, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", "", fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+	case r := <-done:
+		return r.stdOut, r.stdErr, r.err
+	}
+}
@@ -0,0 +1,40 @@
+// Package winrm runs a PowerShell command on a remote Windows host over
+// WinRM. It is used where the host we need to act on is not the one crc is
+// running on, for example the Hyper-V parent host of a nested crc VM.
+package winrm
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/masterzen/winrm"
+)
+
+// HostConfig identifies a remote host and the credentials to use to reach
+// it over WinRM.
+type HostConfig struct {
+	FQDNOrIP string
+	User     string
+	Password string
+}
+
+// Run executes cmd on host over WinRM and returns its stdout. A non-nil
+// error means either the host could not be reached or the command itself
+// returned a non-zero exit code.
+func Run(host HostConfig, cmd string) (string, error) {
+	endpoint := winrm.NewEndpoint(host.FQDNOrIP, 5985, false, false, nil, nil, nil, 0)
+	client, err := winrm.NewClient(endpoint, host.User, host.Password)
+	if err != nil {
+		return "", fmt.Errorf("could not create winrm client for %s: %w", host.FQDNOrIP, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := client.Run(cmd, &stdout, &stderr)
+	if err != nil {
+		return "", fmt.Errorf("could not reach %s over winrm: %w", host.FQDNOrIP, err)
+	}
+	if exitCode != 0 {
+		return "", fmt.Errorf("command failed on %s: %s", host.FQDNOrIP, stderr.String())
+	}
+	return stdout.String(), nil
+}
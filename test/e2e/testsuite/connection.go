@@ -0,0 +1,135 @@
+package testsuite
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// PodmanConnection is a typed description of how to reach one CRC
+// instance's podman API, replacing the ad-hoc os.Setenv calls that used to
+// live directly in PodmanCommandIsAvailable. Keeping it as a value lets
+// tests register more than one (e.g. rootful vs rootless, local vs remote
+// host) and switch between them explicitly instead of relying on whichever
+// os.Setenv call happened to run last.
+type PodmanConnection struct {
+	Path          string
+	SSHKey        string
+	ContainerHost string
+	DockerHost    string
+}
+
+// Apply exports the connection into the process environment, which is
+// still how cmd.RunPodmanExpectSuccess and the podman CLI itself pick up
+// CONTAINER_HOST/DOCKER_HOST. Prefer WithConnection where the work using
+// the connection is scoped to a single call, since Apply leaves the
+// mutation in place for whoever runs next.
+func (c PodmanConnection) Apply() {
+	os.Setenv("PATH", c.Path)
+	os.Setenv("CONTAINER_SSHKEY", c.SSHKey)
+	os.Setenv("CONTAINER_HOST", c.ContainerHost)
+	os.Setenv("DOCKER_HOST", c.DockerHost)
+}
+
+// Env returns the connection's settings as process-environment-style
+// KEY=VALUE pairs, for callers that can feed them directly into a child
+// process's environment (e.g. exec.Cmd.Env) instead of mutating the whole
+// process via Apply.
+func (c PodmanConnection) Env() []string {
+	return []string{
+		fmt.Sprintf("PATH=%s", c.Path),
+		fmt.Sprintf("CONTAINER_SSHKEY=%s", c.SSHKey),
+		fmt.Sprintf("CONTAINER_HOST=%s", c.ContainerHost),
+		fmt.Sprintf("DOCKER_HOST=%s", c.DockerHost),
+	}
+}
+
+// applyMu serializes every WithConnection call. cmd.RunPodmanExpectSuccess
+// and the podman CLI only ever look at CONTAINER_HOST/DOCKER_HOST in the
+// process environment - there is no explicit env slice to feed them - so
+// this is still a process-global mutation underneath. Holding one lock for
+// the whole apply/work/restore sequence stops two WithConnection calls
+// from interleaving. Apply calls made outside WithConnection (by
+// UsePodmanConnectionSucceedsOrFails and PodmanCommandIsAvailable) do not
+// take this lock.
+var applyMu sync.Mutex
+
+// WithConnection runs fn with c applied to the process environment,
+// restoring whatever was there before once fn returns.
+func (c PodmanConnection) WithConnection(fn func() error) error {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	saved := snapshotPodmanEnv()
+	defer saved.restore()
+
+	c.Apply()
+	return fn()
+}
+
+var (
+	connectionsMu        sync.Mutex
+	connections          = map[string]PodmanConnection{}
+	activeConnectionName = "default"
+)
+
+// RegisterPodmanConnection makes conn available under name for later use
+// with UsePodmanConnectionSucceedsOrFails.
+func RegisterPodmanConnection(name string, conn PodmanConnection) {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+	connections[name] = conn
+}
+
+// RemovePodmanConnection forgets the connection registered as name.
+func RemovePodmanConnection(name string) {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+	delete(connections, name)
+}
+
+// UsePodmanConnectionSucceedsOrFails switches the active podman connection
+// to the one registered as name.
+func UsePodmanConnectionSucceedsOrFails(name string) error {
+	connectionsMu.Lock()
+	conn, ok := connections[name]
+	connectionsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no podman connection registered as %q", name)
+	}
+	activeConnectionName = name
+	conn.Apply()
+	return nil
+}
+
+// withActiveConnection runs fn with the currently selected podman
+// connection applied, if one was registered. Steps that immediately run a
+// podman/docker command should go through this instead of relying on
+// whatever Apply call happened to run last.
+func withActiveConnection(fn func() error) error {
+	connectionsMu.Lock()
+	conn, ok := connections[activeConnectionName]
+	connectionsMu.Unlock()
+	if !ok {
+		return fn()
+	}
+	return conn.WithConnection(fn)
+}
+
+// savedPodmanEnv snapshots the env vars a PodmanConnection touches, so a
+// scenario's choice of connection doesn't leak into the next one.
+type savedPodmanEnv map[string]string
+
+func snapshotPodmanEnv() savedPodmanEnv {
+	saved := savedPodmanEnv{}
+	for _, name := range []string{"PATH", "CONTAINER_SSHKEY", "CONTAINER_HOST", "DOCKER_HOST"} {
+		saved[name] = os.Getenv(name)
+	}
+	return saved
+}
+
+func (saved savedPodmanEnv) restore() {
+	for name, value := range saved {
+		os.Setenv(name, value)
+	}
+}
@@ -0,0 +1,199 @@
+package testsuite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	crcCmd "github.com/crc-org/crc/test/extended/crc/cmd"
+	"github.com/crc-org/crc/test/extended/util"
+)
+
+// ExecutingContainerRuntimeCommandSucceedsOrFails runs command through
+// either the podman or docker CLI, mirroring ExecutingPodmanCommandSucceedsFails
+// but without hardcoding podman as the only supported runtime.
+func ExecutingContainerRuntimeCommandSucceedsOrFails(runtimeName string, command string, expected string) error {
+	args := strings.Split(command[1:len(command)-1], " ")
+
+	return withActiveConnection(func() error {
+		var err error
+		switch runtimeName {
+		case "podman":
+			if expected == "succeeds" {
+				_, err = crcCmd.RunPodmanExpectSuccess(args...)
+			} else {
+				_, err = crcCmd.RunPodmanExpectFail(args...)
+			}
+		case "docker":
+			if expected == "succeeds" {
+				_, err = crcCmd.RunDockerExpectSuccess(args...)
+			} else {
+				_, err = crcCmd.RunDockerExpectFail(args...)
+			}
+		default:
+			return fmt.Errorf("unsupported container runtime %q: expected podman or docker", runtimeName)
+		}
+		return err
+	})
+}
+
+// ContainerRuntimeIsRootless checks whether the given runtime's daemon/CLI
+// on the CRC VM is running as a non-root user, which changes where its
+// socket lives and which steps are valid against it (e.g. rootless podman
+// exposes its API socket under /run/user/<uid>, not /run).
+func ContainerRuntimeIsRootless(runtimeName string, expected string) error {
+	var infoCommand string
+	switch runtimeName {
+	case "podman":
+		infoCommand = "podman info --format '{{.Host.Security.Rootless}}'"
+	case "docker":
+		infoCommand = "docker info --format '{{.SecurityOptions}}'"
+	default:
+		return fmt.Errorf("unsupported container runtime %q: expected podman or docker", runtimeName)
+	}
+
+	if err := util.ExecuteCommand(infoCommand); err != nil {
+		return err
+	}
+	out := strings.TrimSpace(util.GetLastCommandOutput("stdout"))
+
+	isRootless := strings.Contains(out, "true") || strings.Contains(out, "rootless")
+	if expected == "rootless" && !isRootless {
+		return fmt.Errorf("expected %s to be running rootless, got: %s", runtimeName, out)
+	}
+	if expected == "rootful" && isRootless {
+		return fmt.Errorf("expected %s to be running rootful, got: %s", runtimeName, out)
+	}
+	return nil
+}
+
+// ContainerEngine identifies which container CLI on the CRC VM a scenario
+// should drive commands through, and whether it is running rootless.
+// Scenarios tagged @requires-engine(name) or @rootless use this instead of
+// a step hardcoding podman, so they keep working on VMs where nerdctl is
+// the only engine installed, or where podman is running rootful.
+type ContainerEngine struct {
+	Name     string
+	Rootless bool
+}
+
+// detectedEngine caches DetectContainerEngine's result for the life of the
+// process, since probing the VM for each available engine is slow and the
+// set of installed engines doesn't change mid-run.
+var detectedEngine *ContainerEngine
+
+var engineTagRE = regexp.MustCompile(`^@requires-engine\((.*)\)$`)
+
+// DetectContainerEngine probes the CRC VM for its available container
+// engines and returns the one scenarios should use by default: rootless
+// podman if present, since that has been CRC's default since the bundle
+// moved its default user off root, then rootful podman, then nerdctl as
+// the containerd-native alternative.
+func DetectContainerEngine() (ContainerEngine, error) {
+	if detectedEngine != nil {
+		return *detectedEngine, nil
+	}
+
+	candidates := []ContainerEngine{
+		{Name: "podman", Rootless: true},
+		{Name: "podman", Rootless: false},
+		{Name: "nerdctl", Rootless: false},
+	}
+
+	for _, candidate := range candidates {
+		if err := util.ExecuteCommand(fmt.Sprintf("which %s", candidate.Name)); err != nil {
+			continue
+		}
+		if candidate.Name == "podman" {
+			if err := ContainerRuntimeIsRootless("podman", rootlessness(candidate.Rootless)); err != nil {
+				continue
+			}
+		}
+		found := candidate
+		detectedEngine = &found
+		return found, nil
+	}
+
+	return ContainerEngine{}, fmt.Errorf("no supported container engine (rootless/rootful podman or nerdctl) detected on the CRC VM")
+}
+
+func rootlessness(rootless bool) string {
+	if rootless {
+		return "rootless"
+	}
+	return "rootful"
+}
+
+// runViaEngine runs a container CLI subcommand through engine, mirroring
+// ExecutingContainerRuntimeCommandSucceedsOrFails but for the engine
+// DetectContainerEngine selected rather than one a step names explicitly.
+func runViaEngine(engine ContainerEngine, args ...string) (string, error) {
+	switch engine.Name {
+	case "podman":
+		return crcCmd.RunPodmanExpectSuccess(args...)
+	case "docker":
+		return crcCmd.RunDockerExpectSuccess(args...)
+	case "nerdctl":
+		command := "nerdctl " + strings.Join(args, " ")
+		if engine.Rootless {
+			command = "nerdctl --rootless " + strings.Join(args, " ")
+		}
+		if err := util.ExecuteCommand(command); err != nil {
+			return "", err
+		}
+		return util.GetLastCommandOutput("stdout"), nil
+	default:
+		return "", fmt.Errorf("unsupported container engine %q", engine.Name)
+	}
+}
+
+// loginViaEngine authenticates engine against host as whichever user is
+// currently logged into the cluster, rather than assuming "kubeadmin" or
+// assuming podman is the engine in use.
+func loginViaEngine(engine ContainerEngine, host string) error {
+	if err := util.ExecuteCommand("oc whoami"); err != nil {
+		return err
+	}
+	user := strings.TrimSpace(util.GetLastCommandOutput("stdout"))
+
+	if err := util.ExecuteCommand("oc whoami -t"); err != nil {
+		return err
+	}
+	token := strings.TrimSpace(util.GetLastCommandOutput("stdout"))
+
+	_, err := runViaEngine(engine, "login", "-u", user, "-p", token, host, "--tls-verify=false")
+	return err
+}
+
+// PullImageViaContainerEngine pulls image using whichever container engine
+// DetectContainerEngine selected for this run, instead of assuming podman.
+func PullImageViaContainerEngine(image string) error {
+	engine, err := DetectContainerEngine()
+	if err != nil {
+		return err
+	}
+	return withActiveConnection(func() error {
+		_, err := runViaEngine(engine, "pull", image)
+		return err
+	})
+}
+
+// ImageIsPresentViaContainerEngine checks that image has already been
+// pulled or built into whichever container engine DetectContainerEngine
+// selected for this run.
+func ImageIsPresentViaContainerEngine(image string) error {
+	engine, err := DetectContainerEngine()
+	if err != nil {
+		return err
+	}
+	return withActiveConnection(func() error {
+		out, err := runViaEngine(engine, "images", "--format", "{{.Repository}}:{{.Tag}}")
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(out, image) {
+			return fmt.Errorf("image %q is not present in %s", image, engine.Name)
+		}
+		return nil
+	})
+}
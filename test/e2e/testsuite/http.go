@@ -0,0 +1,187 @@
+package testsuite
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crc-org/crc/test/extended/util"
+)
+
+// HTTPProbeOptions configures how an HTTP(S) endpoint is probed by the
+// retry-aware steps below. It replaces the unconditional
+// InsecureSkipVerify: true behaviour so that tests against a real
+// OpenShift route (edge-terminated, re-encrypt, passthrough with client
+// auth) can be expressed without reaching for raw curl commands.
+type HTTPProbeOptions struct {
+	// CACertPath, when set, is loaded into the client's RootCAs instead of
+	// skipping TLS verification altogether.
+	CACertPath string
+	// ClientCertPath/ClientKeyPath configure mTLS against the endpoint.
+	ClientCertPath string
+	ClientKeyPath  string
+	// HostHeader overrides the Host header (and TLS SNI ServerName) sent
+	// with the request, for testing routes by name without DNS.
+	HostHeader string
+	// Timeout bounds a single attempt, separate from the retry wait period.
+	Timeout time.Duration
+}
+
+func (o HTTPProbeOptions) newClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+	if o.CACertPath != "" {
+		pem, err := os.ReadFile(o.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle %s: %v", o.CACertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	} else {
+		// #nosec G402 - no CA bundle was provided, fall back to the
+		// previous insecure-by-default behaviour of this test helper
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if o.ClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(o.ClientCertPath, o.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate %s / %s: %v", o.ClientCertPath, o.ClientKeyPath, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if o.HostHeader != "" {
+		tlsConfig.ServerName = o.HostHeader
+	}
+
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   timeout,
+	}, nil
+}
+
+func (o HTTPProbeOptions) newRequest(address string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if o.HostHeader != "" {
+		req.Host = o.HostHeader
+	}
+	return req, nil
+}
+
+// probeHTTP runs a single probe attempt, invoking assert on the response
+// when the request itself succeeded.
+func probeHTTP(address string, options HTTPProbeOptions, assert func(*http.Response) error) error {
+	client, err := options.newClient()
+	if err != nil {
+		return err
+	}
+	req, err := options.newRequest(address)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return assert(resp)
+}
+
+func retryHTTPProbe(retryCount int, retryWait string, address string, options HTTPProbeOptions, assert func(*http.Response) error) error {
+	retryDuration, err := time.ParseDuration(retryWait)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= retryCount; attempt++ {
+		lastErr = probeHTTP(address, options, assert)
+		if lastErr == nil {
+			return nil
+		}
+		_ = util.LogMessage("debug", fmt.Sprintf("attempt %d/%d against %s failed: %v", attempt, retryCount, address, lastErr))
+		time.Sleep(retryDuration)
+	}
+	return lastErr
+}
+
+// CheckHTTPResponseWithRetry checks that address responds with
+// expectedStatusCode, retrying retryCount times with retryWait between
+// attempts.
+func CheckHTTPResponseWithRetry(retryCount int, retryWait string, address string, expectedStatusCode int) error {
+	return retryHTTPProbe(retryCount, retryWait, address, HTTPProbeOptions{}, func(resp *http.Response) error {
+		if resp.StatusCode != expectedStatusCode {
+			return fmt.Errorf("got %d as Status Code instead of expected %d", resp.StatusCode, expectedStatusCode)
+		}
+		return nil
+	})
+}
+
+// CheckHTTPResponseBodyWithRetry checks address's response body against
+// expectedContent, either as a substring ("contains") or a regular
+// expression ("matches").
+func CheckHTTPResponseBodyWithRetry(retryCount int, retryWait string, address string, condition string, expectedContent string) error {
+	return retryHTTPProbe(retryCount, retryWait, address, HTTPProbeOptions{}, func(resp *http.Response) error {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("could not read response body from %s: %v", address, err)
+		}
+
+		if strings.Contains(condition, "match") {
+			matches, err := util.PerformRegexMatch(expectedContent, string(body))
+			if err != nil {
+				return err
+			}
+			if !matches {
+				return fmt.Errorf("response body from %s does not match %q", address, expectedContent)
+			}
+			return nil
+		}
+
+		if !strings.Contains(string(body), expectedContent) {
+			return fmt.Errorf("response body from %s does not contain %q", address, expectedContent)
+		}
+		return nil
+	})
+}
+
+// CheckHTTPResponseHeaderWithRetry checks that address's response carries
+// headerName set to expectedValue.
+func CheckHTTPResponseHeaderWithRetry(retryCount int, retryWait string, address string, headerName string, expectedValue string) error {
+	return retryHTTPProbe(retryCount, retryWait, address, HTTPProbeOptions{}, func(resp *http.Response) error {
+		actual := resp.Header.Get(headerName)
+		if actual != expectedValue {
+			return fmt.Errorf("header %q from %s is %q instead of expected %q", headerName, address, actual, expectedValue)
+		}
+		return nil
+	})
+}
+
+// CheckHTTPResponseWithClientCertWithRetry checks address's response status
+// code while presenting the given client certificate/key pair for mTLS,
+// as required by routes that terminate TLS with client auth enabled.
+func CheckHTTPResponseWithClientCertWithRetry(retryCount int, retryWait string, address string, certPath string, keyPath string, expectedStatusCode int) error {
+	options := HTTPProbeOptions{ClientCertPath: certPath, ClientKeyPath: keyPath}
+	return retryHTTPProbe(retryCount, retryWait, address, options, func(resp *http.Response) error {
+		if resp.StatusCode != expectedStatusCode {
+			return fmt.Errorf("got %d as Status Code instead of expected %d", resp.StatusCode, expectedStatusCode)
+		}
+		return nil
+	})
+}
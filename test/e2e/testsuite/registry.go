@@ -0,0 +1,122 @@
+package testsuite
+
+import (
+	"fmt"
+	"strings"
+
+	crcCmd "github.com/crc-org/crc/test/extended/crc/cmd"
+	"github.com/crc-org/crc/test/extended/util"
+)
+
+// registryClient talks to the cluster's internal image registry, whose
+// route and credentials used to be hardcoded as
+// "default-route-openshift-image-registry.apps-crc.testing" /
+// "kubeadmin". Discovering them instead keeps the step working against
+// clusters where the default route name, the logged in user, or the apps
+// domain differ from the CRC defaults.
+type registryClient struct {
+	host string
+}
+
+// discoverRegistry finds the internal registry's externally reachable
+// route via `oc registry info`.
+func discoverRegistry() (*registryClient, error) {
+	if err := util.ExecuteCommand("oc registry info"); err != nil {
+		return nil, fmt.Errorf("could not discover internal registry route: %v", err)
+	}
+	host := strings.TrimSpace(util.GetLastCommandOutput("stdout"))
+	if host == "" {
+		return nil, fmt.Errorf("internal registry route is empty, is the image registry operator exposed?")
+	}
+	return &registryClient{host: host}, nil
+}
+
+// login authenticates podman against the registry as whichever user is
+// currently logged into the cluster, rather than assuming "kubeadmin".
+func (r *registryClient) login() error {
+	if err := util.ExecuteCommand("oc whoami"); err != nil {
+		return err
+	}
+	user := strings.TrimSpace(util.GetLastCommandOutput("stdout"))
+
+	if err := util.ExecuteCommand("oc whoami -t"); err != nil {
+		return err
+	}
+	token := strings.TrimSpace(util.GetLastCommandOutput("stdout"))
+
+	_, err := crcCmd.RunPodmanExpectSuccess("login", "-u", user, "-p", token, r.host, "--tls-verify=false")
+	return err
+}
+
+// imageRef builds a fully qualified reference to project/name:tag on this
+// registry.
+func (r *registryClient) imageRef(project string, name string, tag string) string {
+	return fmt.Sprintf("%s/%s/%s:%s", r.host, project, name, tag)
+}
+
+// BuildFromContainerfilePushSucceedsOrFails builds contextDir using the
+// Containerfile at containerfilePath, then pushes the result as
+// project/name:tag on CRC's internal registry. This exercises the same
+// mirrored-registry path as PullLoginTagPushImageSucceeds, but for images
+// built locally instead of pulled from an external source.
+func BuildFromContainerfilePushSucceedsOrFails(containerfilePath string, contextDir string, project string, name string, tag string, expected string) error {
+	registry, err := discoverRegistry()
+	if err != nil {
+		return err
+	}
+	targetImage := registry.imageRef(project, name, tag)
+
+	buildArgs := []string{"build", "-f", containerfilePath, "-t", targetImage, contextDir}
+	if expected == "fails" {
+		_, err := crcCmd.RunPodmanExpectFail(buildArgs...)
+		return err
+	}
+
+	if _, err := crcCmd.RunPodmanExpectSuccess(buildArgs...); err != nil {
+		return err
+	}
+	if err := registry.login(); err != nil {
+		return err
+	}
+	_, err = crcCmd.RunPodmanExpectSuccess("push", targetImage, "--tls-verify=false")
+	return err
+}
+
+// BuildFromContainerfileForPlatformPushSucceedsOrFails is the cross-arch
+// counterpart of BuildFromContainerfilePushSucceedsOrFails: it passes
+// --platform through to `podman build` so the result can target an
+// architecture other than the host's, then asserts via `podman inspect`
+// that the built image actually reports that platform before pushing it,
+// since an unsupported --platform value can silently fall back to the
+// host's architecture instead of failing the build.
+func BuildFromContainerfileForPlatformPushSucceedsOrFails(containerfilePath string, contextDir string, platform string, project string, name string, tag string, expected string) error {
+	registry, err := discoverRegistry()
+	if err != nil {
+		return err
+	}
+	targetImage := registry.imageRef(project, name, tag)
+
+	buildArgs := []string{"build", "--platform", platform, "-f", containerfilePath, "-t", targetImage, contextDir}
+	if expected == "fails" {
+		_, err := crcCmd.RunPodmanExpectFail(buildArgs...)
+		return err
+	}
+
+	if _, err := crcCmd.RunPodmanExpectSuccess(buildArgs...); err != nil {
+		return err
+	}
+
+	built, err := crcCmd.RunPodmanExpectSuccess("inspect", "--format", "{{.Os}}/{{.Architecture}}", targetImage)
+	if err != nil {
+		return err
+	}
+	if gotPlatform := strings.TrimSpace(built); gotPlatform != platform {
+		return fmt.Errorf("built image %s reports platform %q, expected %q", targetImage, gotPlatform, platform)
+	}
+
+	if err := registry.login(); err != nil {
+		return err
+	}
+	_, err = crcCmd.RunPodmanExpectSuccess("push", targetImage, "--tls-verify=false")
+	return err
+}
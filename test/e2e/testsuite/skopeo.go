@@ -0,0 +1,60 @@
+package testsuite
+
+import (
+	"fmt"
+	"strings"
+
+	crcCmd "github.com/crc-org/crc/test/extended/crc/cmd"
+)
+
+// CopyImageViaSkopeoSucceedsOrFails copies srcImage to destImage with
+// skopeo instead of a pull+tag+push round trip through the container
+// engine. skopeo copies the source manifest as-is, including OCI-format
+// and multi-arch manifest lists, instead of the engine flattening it down
+// to whichever single architecture it pulled.
+func CopyImageViaSkopeoSucceedsOrFails(srcImage string, destImage string, expected string) error {
+	args := []string{
+		"copy", "--all",
+		fmt.Sprintf("docker://%s", srcImage),
+		fmt.Sprintf("docker://%s", destImage),
+		"--dest-tls-verify=false",
+	}
+	return withActiveConnection(func() error {
+		if expected == "fails" {
+			_, err := crcCmd.RunSkopeoExpectFail(args...)
+			return err
+		}
+		_, err := crcCmd.RunSkopeoExpectSuccess(args...)
+		return err
+	})
+}
+
+// PushMultiArchManifestListSucceedsOrFails assembles an OCI-format
+// manifest list out of archImages (each already present locally, one per
+// architecture) and pushes it to destImage as a single multi-arch
+// reference, via `podman manifest create`/`manifest add`/`manifest push`
+// rather than pushing one architecture's image and losing the others.
+func PushMultiArchManifestListSucceedsOrFails(destImage string, archImages string, expected string) error {
+	return withActiveConnection(func() error {
+		if _, err := crcCmd.RunPodmanExpectSuccess("manifest", "create", destImage); err != nil {
+			return err
+		}
+		for _, archImage := range strings.Split(archImages, ",") {
+			if _, err := crcCmd.RunPodmanExpectSuccess("manifest", "add", destImage, strings.TrimSpace(archImage)); err != nil {
+				return err
+			}
+		}
+
+		args := []string{
+			"manifest", "push", "--all", "--format", "oci",
+			destImage, fmt.Sprintf("docker://%s", destImage),
+			"--tls-verify=false",
+		}
+		if expected == "fails" {
+			_, err := crcCmd.RunPodmanExpectFail(args...)
+			return err
+		}
+		_, err := crcCmd.RunPodmanExpectSuccess(args...)
+		return err
+	})
+}
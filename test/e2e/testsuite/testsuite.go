@@ -2,12 +2,11 @@ package testsuite
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"net/http"
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -37,8 +36,33 @@ var (
 	GodogStopOnFailure       bool
 	GodogNoColors            bool
 	GodogPaths               string
+
+	runnerKind string
+
+	presetsFlag string
+	// CurrentPreset is the preset the running worker was started for. It is
+	// set by InitializeTestSuite when --preset lists a single value, and
+	// overridden per-invocation when the suite runner iterates presets.
+	CurrentPreset preset.Preset
+
+	parallelWorkers int
+	workerPool      *util.WorkerPool
+
+	// cachedBundles is shared across every BeforeSuite run in this process
+	// (one per preset when RunSuite iterates presets), so that the same
+	// bundle is downloaded at most once instead of once per preset.
+	cachedBundles = util.NewBundlePool()
 )
 
+type contextKey string
+
+const (
+	workerContextKey    contextKey = "crc-worker"
+	podmanEnvContextKey contextKey = "podman-env"
+)
+
+var presetTagRE = regexp.MustCompile(`^@preset\((.*)\)$`)
+
 func ParseFlags() {
 
 	pflag.StringVar(&util.TestDir, "test-dir", "out", "Path to the directory in which to execute the tests")
@@ -48,6 +72,64 @@ func ParseFlags() {
 	pflag.StringVar(&pullSecretFile, "pull-secret-file", "/path/to/pull-secret", "Path to the file containing pull secret")
 	pflag.StringVar(&CRCExecutable, "crc-binary", "/path/to/binary/crc", "Path to the CRC executable to be tested")
 	pflag.BoolVar(&cleanupHome, "cleanup-home", false, "Try to remove crc home folder before starting the suite") // TODO: default=true
+
+	pflag.StringVar(&runnerKind, "runner", "local", "Which OS dialect (PowerShell vs POSIX sh) steps should assume commands run under: local, ssh (the CRC VM, always Linux), or winrm (a remote Windows host). Steps still execute on the local host either way.")
+
+	pflag.StringVar(&presetsFlag, "preset", string(preset.OpenShift), "Comma-separated list of presets to run the suite against (openshift, microshift, okd)")
+
+	pflag.IntVar(&parallelWorkers, "parallel", 1, "Number of scenarios to run concurrently, each against its own isolated CRC home directory")
+}
+
+// Presets returns the list of presets requested via --preset, so the suite
+// runner can iterate them and produce one JUnit report per preset.
+func Presets() []preset.Preset {
+	var presets []preset.Preset
+	for _, name := range strings.Split(presetsFlag, ",") {
+		presets = append(presets, preset.Preset(strings.TrimSpace(name)))
+	}
+	return presets
+}
+
+// RunSuite runs the scenario suite once per preset returned by Presets,
+// setting CurrentPreset before each run so InitializeTestSuite and the
+// @preset(...) tag filtering in InitializeScenario pick the right bundle and
+// scenarios, and writing each preset's results to its own JUnit file instead
+// of one combined report that would hide which preset a failure came from.
+// There is no cmd/ entrypoint in this tree yet to call it from; it is meant
+// to replace a bare single godog.TestSuite{}.Run() call once one exists.
+func RunSuite() int {
+	format := GodogFormat
+	if format == "" {
+		format = "pretty"
+	}
+
+	exitStatus := 0
+	for _, p := range Presets() {
+		CurrentPreset = p
+
+		reportPath := fmt.Sprintf("junit-%s.xml", p)
+		fmt.Printf("----- Running suite for preset %q (report: %s) -----\n", p, reportPath)
+
+		suite := godog.TestSuite{
+			Name:                 fmt.Sprintf("crc-e2e-%s", p),
+			TestSuiteInitializer: InitializeTestSuite,
+			ScenarioInitializer:  InitializeScenario,
+			Options: &godog.Options{
+				Format:              fmt.Sprintf("%s,junit:%s", format, reportPath),
+				Tags:                GodogTags,
+				Paths:               strings.Split(GodogPaths, ","),
+				ShowStepDefinitions: GodogShowStepDefinitions,
+				StopOnFailure:       GodogStopOnFailure,
+				NoColors:            GodogNoColors,
+				Concurrency:         parallelWorkers,
+			},
+		}
+
+		if status := suite.Run(); status > exitStatus {
+			exitStatus = status
+		}
+	}
+	return exitStatus
 }
 
 func InitializeTestSuite(tctx *godog.TestSuiteContext) {
@@ -60,9 +142,22 @@ func InitializeTestSuite(tctx *godog.TestSuiteContext) {
 			os.Exit(1)
 		}
 
+		if err := util.SetRunnerTargetOS(runnerKind); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
 		usr, _ := user.Current()
 		CRCHome = filepath.Join(usr.HomeDir, ".crc")
 
+		if parallelWorkers > 1 {
+			homes := make([]string, parallelWorkers)
+			for i := range homes {
+				homes[i] = filepath.Join(usr.HomeDir, fmt.Sprintf(".crc-worker-%d", i))
+			}
+			workerPool = util.NewWorkerPool(homes)
+		}
+
 		// init CRCExecutable if no location provided by user
 		if CRCExecutable == "" {
 			fmt.Println("Expecting the CRC executable to be in $HOME/go/bin.")
@@ -92,10 +187,23 @@ func InitializeTestSuite(tctx *godog.TestSuiteContext) {
 			return
 		}
 
+		if CurrentPreset == "" {
+			// RunSuite sets CurrentPreset itself before each preset's run; this
+			// is the direct InitializeTestSuite path (no cmd/ entrypoint calls
+			// RunSuite in this tree yet), so read the single preset requested
+			// via --preset here instead of always defaulting to OpenShift.
+			presets := Presets()
+			if len(presets) != 1 {
+				fmt.Printf("Expecting exactly one --preset for a single suite run, got %q\n", presetsFlag)
+				os.Exit(1)
+			}
+			CurrentPreset = presets[0]
+		}
+
 		if bundleLocation == "" {
 			fmt.Println("Expecting the bundle provided by the user")
 			userProvidedBundle = false
-			bundleName = constants.GetDefaultBundle(preset.OpenShift)
+			bundleName = constants.GetDefaultBundle(CurrentPreset)
 		} else {
 			userProvidedBundle = true
 			_, bundleName = filepath.Split(bundleLocation)
@@ -121,9 +229,13 @@ func InitializeTestSuite(tctx *godog.TestSuiteContext) {
 					fmt.Printf("Unexpected error obtaining the bundle %v.\n", bundleLocation)
 					os.Exit(1)
 				}
-				// Obtain the bundle to current dir
+				// Obtain the bundle to current dir, reusing it if another
+				// preset/worker in this run already downloaded it
 				fmt.Println("Obtaining bundle...")
-				bundleLocation, err = util.DownloadBundle(bundleLocation, ".", bundleName)
+				wantedLocation := bundleLocation
+				bundleLocation, err = cachedBundles.Resolve(bundleName, func() (string, error) {
+					return util.DownloadBundle(wantedLocation, ".", bundleName)
+				})
 				if err != nil {
 					fmt.Printf("Failed to obtain CRC bundle, %v\n", err)
 					os.Exit(1)
@@ -158,6 +270,11 @@ func InitializeScenario(s *godog.ScenarioContext) {
 
 	s.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 
+		if workerPool != nil {
+			ctx = context.WithValue(ctx, workerContextKey, workerPool.Acquire())
+		}
+		ctx = context.WithValue(ctx, podmanEnvContextKey, snapshotPodmanEnv())
+
 		err := util.StartHostShellInstance(testWithShell)
 		if err != nil {
 			fmt.Println("error starting host shell instance:", err)
@@ -179,6 +296,34 @@ func InitializeScenario(s *godog.ScenarioContext) {
 			fmt.Println("error logging:", err)
 		}
 
+		for _, tag := range sc.Tags {
+			// skip the scenario when it is tied to a preset that does not
+			// match the one this worker was started for
+			if matches := presetTagRE.FindStringSubmatch(tag.Name); matches != nil {
+				wanted := strings.Split(matches[1], "|")
+				if !presetIsWanted(wanted) {
+					return ctx, godog.ErrSkip
+				}
+			}
+
+			// skip the scenario unless the container engine this worker
+			// detected on the CRC VM is the one it requires
+			if tag.Name == "@rootless" || engineTagRE.MatchString(tag.Name) {
+				engine, err := DetectContainerEngine()
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				if tag.Name == "@rootless" && !engine.Rootless {
+					return ctx, godog.ErrSkip
+				}
+				if matches := engineTagRE.FindStringSubmatch(tag.Name); matches != nil && matches[1] != engine.Name {
+					return ctx, godog.ErrSkip
+				}
+			}
+		}
+		util.SetScenarioVariable("PRESET", string(CurrentPreset))
+
 		for _, tag := range sc.Tags {
 			// copy data/config files to test dir
 			if tag.Name == "@testdata" {
@@ -360,6 +505,8 @@ func InitializeScenario(s *godog.ScenarioContext) {
 		RemoveCRCHome)
 	s.Step(`^starting CRC with default bundle (succeeds|fails)$`,
 		StartCRCWithDefaultBundleSucceedsOrFails)
+	s.Step(`^starting CRC with preset "(.*)" (succeeds|fails)$`,
+		StartCRCWithPresetSucceedsOrFails)
 	s.Step(`^starting CRC with custom bundle (succeeds|fails)$`,
 		StartCRCWithCustomBundleSucceedsOrFails)
 	s.Step(`^starting CRC with default bundle along with stopped network time synchronization (succeeds|fails)$`,
@@ -376,6 +523,12 @@ func InitializeScenario(s *godog.ScenarioContext) {
 		SetKubeConfigContextSucceedsOrFails)
 	s.Step(`^with up to "(\d+)" retries with wait period of "(\d*(?:ms|s|m))" http response from "(.*)" has status code "(\d+)"$`,
 		CheckHTTPResponseWithRetry)
+	s.Step(`^with up to "(\d+)" retries with wait period of "(\d*(?:ms|s|m))" http response from "(.*)" body (should contain|contains|should match|matches) "(.*)"$`,
+		CheckHTTPResponseBodyWithRetry)
+	s.Step(`^with up to "(\d+)" retries with wait period of "(\d*(?:ms|s|m))" http response from "(.*)" header "(.*)" equals "(.*)"$`,
+		CheckHTTPResponseHeaderWithRetry)
+	s.Step(`^with up to "(\d+)" retries with wait period of "(\d*(?:ms|s|m))" http response from "(.*)" using client cert "(.*)" and key "(.*)" has status code "(\d+)"$`,
+		CheckHTTPResponseWithClientCertWithRetry)
 	s.Step(`^with up to "(\d+)" retries with wait period of "(\d*(?:ms|s|m))" command "(.*)" output (should match|matches|should not match|does not match) "(.*)"$`,
 		CheckOutputMatchWithRetry)
 	s.Step(`^checking that CRC is (running|stopped)$`,
@@ -388,16 +541,34 @@ func InitializeScenario(s *godog.ScenarioContext) {
 		ExecuteSingleCommandWithExpectedExitStatus)
 	s.Step(`^execut(?:e|ing) podman command (.*) (succeeds|fails)$`,
 		ExecutingPodmanCommandSucceedsFails)
+	s.Step(`^execut(?:e|ing) (podman|docker) command (.*) (succeeds|fails)$`,
+		ExecutingContainerRuntimeCommandSucceedsOrFails)
+	s.Step(`^(podman|docker) is running (rootless|rootful)$`,
+		ContainerRuntimeIsRootless)
 	s.Step(`^ensuring CRC cluster is running (succeeds|fails)$`,
 		EnsureCRCIsRunningSucceedsOrFails)
 	s.Step(`^ensuring user is logged in (succeeds|fails)`,
 		EnsureUserIsLoggedIntoClusterSucceedsOrFails)
 	s.Step(`^podman command is available$`,
 		PodmanCommandIsAvailable)
+	s.Step(`^using podman connection "(.*)"$`,
+		UsePodmanConnectionSucceedsOrFails)
 	s.Step(`^deleting a pod (succeeds|fails)$`,
 		DeletingPodSucceedsOrFails)
 	s.Step(`^pulling image "(.*)", logging in, and pushing local image to internal registry succeeds$`,
 		PullLoginTagPushImageSucceeds)
+	s.Step(`^pulling image "(.*)" via container engine succeeds$`,
+		PullImageViaContainerEngine)
+	s.Step(`^image "(.*)" is present$`,
+		ImageIsPresentViaContainerEngine)
+	s.Step(`^copying image "(.*)" to "(.*)" via skopeo (succeeds|fails)$`,
+		CopyImageViaSkopeoSucceedsOrFails)
+	s.Step(`^pushing a multi-arch manifest list "(.*)" for images "(.*)" to internal registry (succeeds|fails)$`,
+		PushMultiArchManifestListSucceedsOrFails)
+	s.Step(`^building image from Containerfile "(.*)" in directory "(.*)" and pushing "(.*)/(.*):(.*)" to internal registry (succeeds|fails)$`,
+		BuildFromContainerfilePushSucceedsOrFails)
+	s.Step(`^building image from Containerfile "(.*)" in directory "(.*)" for platform "(.*)" and pushing "(.*)/(.*):(.*)" to internal registry (succeeds|fails)$`,
+		BuildFromContainerfileForPlatformPushSucceedsOrFails)
 
 	// CRC file operations
 	s.Step(`^file "([^"]*)" exists in CRC home folder$`,
@@ -418,8 +589,10 @@ func InitializeScenario(s *godog.ScenarioContext) {
 			return ctx, nil
 		}
 		if err != nil {
-			if err := util.RunDiagnose(filepath.Join("..", "test-results")); err != nil {
-				fmt.Printf("Failed to collect diagnostic: %v\n", err)
+			if bundlePath, collectErr := util.CollectDiagnostics(ctx, sc.Name, filepath.Join("..", "test-results")); collectErr != nil {
+				fmt.Printf("Failed to collect diagnostics: %v\n", collectErr)
+			} else {
+				fmt.Printf("Diagnostics bundle written to %s\n", bundlePath)
 			}
 		}
 
@@ -428,6 +601,13 @@ func InitializeScenario(s *godog.ScenarioContext) {
 			fmt.Println("error closing host shell instance:", err)
 		}
 
+		if worker, ok := ctx.Value(workerContextKey).(*util.Worker); ok {
+			workerPool.Release(worker)
+		}
+		if saved, ok := ctx.Value(podmanEnvContextKey).(savedPodmanEnv); ok {
+			saved.restore()
+		}
+
 		return ctx, nil
 	})
 
@@ -439,41 +619,70 @@ func usingPreexistingCluster() bool {
 	return strings.Contains(GodogTags, "~@startstop")
 }
 
-func WaitForClusterInState(state string) error {
-	return crcCmd.WaitForClusterInState(state)
+func presetIsWanted(wanted []string) bool {
+	for _, name := range wanted {
+		if preset.Preset(strings.TrimSpace(name)) == CurrentPreset {
+			return true
+		}
+	}
+	return false
 }
 
-func RemoveCRCHome() error {
-	return util.RemoveCRCHome(CRCHome)
+// StartCRCWithPresetSucceedsOrFails starts CRC against the given preset,
+// passing it through to `crc start --preset`. It runs against the calling
+// scenario's assigned Worker home and VM name when running with
+// --parallel, rather than every worker driving the single default
+// ~/.crc and VM named "crc".
+func StartCRCWithPresetSucceedsOrFails(ctx context.Context, presetName string, expected string) error {
+	var extraBundleArgs string
+	if userProvidedBundle {
+		extraBundleArgs = fmt.Sprintf("-b %s", bundleLocation)
+	}
+	crcStart := crcCmd.CRC("start").ToString()
+	cmd := fmt.Sprintf("%s%s --preset %s -p '%s' --name %s %s",
+		crcHomeEnvPrefix(crcHomeFromContext(ctx)), crcStart, presetName, pullSecretFile, crcVMNameFromContext(ctx), extraBundleArgs)
+	return util.ExecuteCommandSucceedsOrFails(cmd, expected)
 }
 
-func CheckHTTPResponseWithRetry(retryCount int, retryWait string, address string, expectedStatusCode int) error {
-	var err error
+func WaitForClusterInState(state string) error {
+	return crcCmd.WaitForClusterInState(state)
+}
 
-	retryDuration, err := time.ParseDuration(retryWait)
-	if err != nil {
-		return err
-	}
+func RemoveCRCHome(ctx context.Context) error {
+	return util.RemoveCRCHome(crcHomeFromContext(ctx))
+}
 
-	tr := &http.Transport{
-		// #nosec G402
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+// crcHomeFromContext returns the CRC home directory the current scenario
+// should use: its assigned Worker's home when running with --parallel, or
+// the single shared CRCHome otherwise.
+func crcHomeFromContext(ctx context.Context) string {
+	if worker, ok := ctx.Value(workerContextKey).(*util.Worker); ok {
+		return worker.Home
 	}
-	client := &http.Client{Transport: tr}
+	return CRCHome
+}
 
-	var resp *http.Response
-	for i := 0; i < retryCount; i++ {
-		resp, err = client.Get(address)
-		if err == nil && resp.StatusCode == expectedStatusCode {
-			return nil
-		}
-		time.Sleep(retryDuration)
+// crcVMNameFromContext returns the CRC VM name the current scenario should
+// start/target: its assigned Worker's VM name when running with --parallel,
+// so concurrent workers don't all fight over the default "crc" VM, or "crc"
+// (the CRC default) otherwise.
+func crcVMNameFromContext(ctx context.Context) string {
+	if worker, ok := ctx.Value(workerContextKey).(*util.Worker); ok {
+		return worker.VMName
 	}
+	return "crc"
+}
 
-	if err != nil {
-		return err
+// crcHomeEnvPrefix returns the shell snippet that sets CRC_HOME to home for
+// the command that follows it, in whichever dialect the host shell started
+// by StartHostShellInstance speaks - PowerShell on Windows, POSIX sh
+// elsewhere - mirroring the split EnsureUserIsLoggedIntoClusterSucceedsOrFails already
+// uses for `crc oc-env`.
+func crcHomeEnvPrefix(home string) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf(`$Env:CRC_HOME="%s"; `, home)
 	}
-	return fmt.Errorf("got %d as Status Code instead of expected %d", resp.StatusCode, expectedStatusCode)
+	return fmt.Sprintf(`CRC_HOME="%s" `, home)
 }
 
 func CheckOutputMatchWithRetry(retryCount int, retryTime string, command string, expected string, expectedOutput string) error {
@@ -513,9 +722,9 @@ func CheckCRCStatus(state string) error {
 	return crcCmd.CheckCRCStatus(state)
 }
 
-func DeleteFileFromCRCHome(fileName string) error {
+func DeleteFileFromCRCHome(ctx context.Context, fileName string) error {
 
-	theFile := filepath.Join(CRCHome, fileName)
+	theFile := filepath.Join(crcHomeFromContext(ctx), fileName)
 
 	if _, err := os.Stat(theFile); os.IsNotExist(err) {
 		return nil
@@ -527,9 +736,9 @@ func DeleteFileFromCRCHome(fileName string) error {
 	return nil
 }
 
-func FileExistsInCRCHome(fileName string) error {
+func FileExistsInCRCHome(ctx context.Context, fileName string) error {
 
-	theFile := filepath.Join(CRCHome, fileName)
+	theFile := filepath.Join(crcHomeFromContext(ctx), fileName)
 
 	_, err := os.Stat(theFile)
 	if os.IsNotExist(err) {
@@ -539,12 +748,12 @@ func FileExistsInCRCHome(fileName string) error {
 	return err
 }
 
-func ConfigFileInCRCHomeContainsKeyMatchingValue(format string, configFile string, condition string, keyPath string, expectedValue string) error {
+func ConfigFileInCRCHomeContainsKeyMatchingValue(ctx context.Context, format string, configFile string, condition string, keyPath string, expectedValue string) error {
 
 	if expectedValue == "current bundle" {
 		expectedValue = fmt.Sprintf(".*%s", bundleName)
 	}
-	configPath := filepath.Join(CRCHome, configFile)
+	configPath := filepath.Join(crcHomeFromContext(ctx), configFile)
 
 	config, err := util.GetFileContent(configPath)
 	if err != nil {
@@ -568,9 +777,9 @@ func ConfigFileInCRCHomeContainsKeyMatchingValue(format string, configFile strin
 	return nil
 }
 
-func ConfigFileInCRCHomeContainsKey(format string, configFile string, condition string, keyPath string) error {
+func ConfigFileInCRCHomeContainsKey(ctx context.Context, format string, configFile string, condition string, keyPath string) error {
 
-	configPath := filepath.Join(CRCHome, configFile)
+	configPath := filepath.Join(crcHomeFromContext(ctx), configFile)
 
 	config, err := util.GetFileContent(configPath)
 	if err != nil {
@@ -609,7 +818,7 @@ func SetKubeConfigContextSucceedsOrFails(context, expected string) error {
 	return util.ExecuteCommandSucceedsOrFails(cmd, expected)
 }
 
-func StartCRCWithDefaultBundleSucceedsOrFails(expected string) error {
+func StartCRCWithDefaultBundleSucceedsOrFails(ctx context.Context, expected string) error {
 
 	var cmd string
 	var extraBundleArgs string
@@ -618,13 +827,14 @@ func StartCRCWithDefaultBundleSucceedsOrFails(expected string) error {
 		extraBundleArgs = fmt.Sprintf("-b %s", bundleLocation)
 	}
 	crcStart := crcCmd.CRC("start").ToString()
-	cmd = fmt.Sprintf("%s -p '%s' %s", crcStart, pullSecretFile, extraBundleArgs)
+	cmd = fmt.Sprintf("%s%s -p '%s' --name %s %s",
+		crcHomeEnvPrefix(crcHomeFromContext(ctx)), crcStart, pullSecretFile, crcVMNameFromContext(ctx), extraBundleArgs)
 	err := util.ExecuteCommandSucceedsOrFails(cmd, expected)
 
 	return err
 }
 
-func StartCRCWithDefaultBundleWithStopNetworkTimeSynchronizationSucceedsOrFails(expected string) error {
+func StartCRCWithDefaultBundleWithStopNetworkTimeSynchronizationSucceedsOrFails(ctx context.Context, expected string) error {
 
 	var cmd string
 	var extraBundleArgs string
@@ -633,19 +843,21 @@ func StartCRCWithDefaultBundleWithStopNetworkTimeSynchronizationSucceedsOrFails(
 		extraBundleArgs = fmt.Sprintf("-b %s", bundleLocation)
 	}
 	crcStart := crcCmd.CRC("start").WithDisableNTP().ToString()
-	cmd = fmt.Sprintf("%s -p '%s' %s", crcStart, pullSecretFile, extraBundleArgs)
+	cmd = fmt.Sprintf("%s%s -p '%s' --name %s %s",
+		crcHomeEnvPrefix(crcHomeFromContext(ctx)), crcStart, pullSecretFile, crcVMNameFromContext(ctx), extraBundleArgs)
 	err := util.ExecuteCommandSucceedsOrFails(cmd, expected)
 
 	return err
 }
 
-func StartCRCWithCustomBundleSucceedsOrFails(expected string) error {
+func StartCRCWithCustomBundleSucceedsOrFails(ctx context.Context, expected string) error {
 	crcStart := crcCmd.CRC("start").ToString()
-	cmd := fmt.Sprintf("%s -p '%s' -b *.crcbundle", crcStart, pullSecretFile)
+	cmd := fmt.Sprintf("%s%s -p '%s' --name %s -b *.crcbundle",
+		crcHomeEnvPrefix(crcHomeFromContext(ctx)), crcStart, pullSecretFile, crcVMNameFromContext(ctx))
 	return util.ExecuteCommandSucceedsOrFails(cmd, expected)
 }
 
-func StartCRCWithDefaultBundleAndNameServerSucceedsOrFails(nameserver string, expected string) error {
+func StartCRCWithDefaultBundleAndNameServerSucceedsOrFails(ctx context.Context, nameserver string, expected string) error {
 
 	var extraBundleArgs string
 	if userProvidedBundle {
@@ -653,10 +865,11 @@ func StartCRCWithDefaultBundleAndNameServerSucceedsOrFails(nameserver string, ex
 	}
 
 	crcStart := crcCmd.CRC("start").ToString()
-	cmd := fmt.Sprintf("%s -n %s -p '%s' %s", crcStart, nameserver, pullSecretFile, extraBundleArgs)
+	cmd := fmt.Sprintf("%s%s -n %s -p '%s' --name %s %s",
+		crcHomeEnvPrefix(crcHomeFromContext(ctx)), crcStart, nameserver, pullSecretFile, crcVMNameFromContext(ctx), extraBundleArgs)
 	return util.ExecuteCommandSucceedsOrFails(cmd, expected)
 }
-func EnsureCRCIsRunningSucceedsOrFails(expected string) error {
+func EnsureCRCIsRunningSucceedsOrFails(ctx context.Context, expected string) error {
 
 	err := crcCmd.WaitForClusterInState("running")
 
@@ -690,10 +903,10 @@ func EnsureCRCIsRunningSucceedsOrFails(expected string) error {
 		return err
 	}
 
-	if runtime.GOOS == "windows" {
-		err = StartCRCWithDefaultBundleAndNameServerSucceedsOrFails("10.75.5.25", expected)
+	if util.ActiveRunnerTargetOS == "windows" {
+		err = StartCRCWithDefaultBundleAndNameServerSucceedsOrFails(ctx, "10.75.5.25", expected)
 	} else {
-		err = StartCRCWithDefaultBundleSucceedsOrFails(expected)
+		err = StartCRCWithDefaultBundleSucceedsOrFails(ctx, expected)
 	}
 	if err != nil {
 		return err
@@ -715,7 +928,7 @@ func EnsureUserIsLoggedIntoClusterSucceedsOrFails(expected string) error {
 
 	var err error
 
-	if runtime.GOOS == "windows" {
+	if util.ActiveRunnerTargetOS == "windows" {
 		err = util.ExecuteCommandSucceedsOrFails("crc oc-env | Invoke-Expression", expected)
 	} else {
 		err = util.ExecuteCommandSucceedsOrFails("eval $(crc oc-env)", expected)
@@ -727,6 +940,12 @@ func EnsureUserIsLoggedIntoClusterSucceedsOrFails(expected string) error {
 	return LoginToOcClusterSucceedsOrFails(expected)
 }
 
+// SetConfigPropertyToValueSucceedsOrFails sets property to value via `crc
+// config set`. Unlike the crc start steps above, this doesn't thread
+// CRC_HOME/the worker's VM name through: crcCmd.SetConfigPropertyToValueSucceedsOrFails
+// and crcCmd.UnsetConfigPropertySucceedsOrFails live in a package this tree
+// has no source for, so there's no visible implementation to add per-worker
+// env to without guessing at its signature and behavior.
 func SetConfigPropertyToValueSucceedsOrFails(property string, value string, expected string) error {
 	if value == "current bundle" {
 		if !userProvidedBundle {
@@ -758,7 +977,7 @@ func ExecuteSingleCommandWithExpectedExitStatus(command string, expectedExitStat
 
 func DeletingPodSucceedsOrFails(expected string) error {
 	var err error
-	if runtime.GOOS == "windows" {
+	if util.ActiveRunnerTargetOS == "windows" {
 		_ = util.ExecuteCommandSucceedsOrFails("$Env:POD = $(oc get pod -o jsonpath=\"{.items[0].metadata.name}\")", expected)
 		err = util.ExecuteCommandSucceedsOrFails("oc delete pod $Env:POD --now", expected)
 	} else {
@@ -768,72 +987,103 @@ func DeletingPodSucceedsOrFails(expected string) error {
 	return err
 }
 
-func PodmanCommandIsAvailable() error {
+func PodmanCommandIsAvailable(ctx context.Context) error {
+
+	env, err := podmanEnv(ctx)
+	if err != nil {
+		return err
+	}
 
-	// Do what 'eval $(crc podman-env) would do
 	path := os.ExpandEnv("${HOME}/.crc/bin/oc:$PATH")
-	csshk := os.ExpandEnv("${HOME}/.crc/machines/crc/id_ecdsa")
-	dh := os.ExpandEnv("unix:///${HOME}/.crc/machines/crc/docker.sock")
-	ch := "ssh://core@127.0.0.1:2222/run/user/1000/podman/podman.sock"
 	if runtime.GOOS == "windows" {
 		userHomeDir, _ := os.UserHomeDir()
-		unexpandedPath := filepath.Join(userHomeDir, ".crc/bin/oc;${PATH}")
-		path = os.ExpandEnv(unexpandedPath)
-		csshk = filepath.Join(userHomeDir, ".crc/machines/crc/id_ecdsa")
-		dh = "npipe:////./pipe/rc-podman"
-	}
-	if runtime.GOOS == "linux" {
-		ch = "ssh://core@192.168.130.11:22/run/user/1000/podman/podman.sock"
+		path = os.ExpandEnv(filepath.Join(userHomeDir, ".crc/bin/oc;${PATH}"))
 	}
 
-	os.Setenv("PATH", path)
-	os.Setenv("CONTAINER_SSHKEY", csshk)
-	os.Setenv("CONTAINER_HOST", ch)
-	os.Setenv("DOCKER_HOST", dh)
+	conn := PodmanConnection{
+		Path:          path,
+		SSHKey:        env["CONTAINER_SSHKEY"],
+		ContainerHost: env["CONTAINER_HOST"],
+		DockerHost:    env["DOCKER_HOST"],
+	}
+	RegisterPodmanConnection("default", conn)
+	conn.Apply()
 
 	return nil
 
 }
 
-func ExecutingPodmanCommandSucceedsFails(command string, expected string) error {
+// podmanEnv runs `crc podman-env` for the scenario's worker - its CRC_HOME
+// and VM name, the same way StartCRCWithPresetSucceedsOrFails does - and
+// parses the `export KEY=VALUE` lines it prints into a map. This replaces
+// reconstructing the VM's SSH host, identity key and podman socket path from
+// constants, which went stale the moment a bundle's VM IP or user changed,
+// and which never accounted for the per-worker VM names --parallel assigns.
+func podmanEnv(ctx context.Context) (map[string]string, error) {
+	cmd := fmt.Sprintf("%scrc podman-env --name %s",
+		crcHomeEnvPrefix(crcHomeFromContext(ctx)), crcVMNameFromContext(ctx))
+	if err := util.ExecuteCommand(cmd); err != nil {
+		return nil, fmt.Errorf("could not run crc podman-env: %w", err)
+	}
 
-	var err error
-	if expected == "succeeds" {
-		_, err = cmd.RunPodmanExpectSuccess(strings.Split(command[1:len(command)-1], " ")...)
-	} else if expected == "fails" {
-		_, err = cmd.RunPodmanExpectFail(strings.Split(command[1:len(command)-1], " ")...)
+	env := make(map[string]string)
+	for _, line := range strings.Split(util.GetLastCommandOutput("stdout"), "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "export ")
+		line = strings.TrimPrefix(line, "$Env:")
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		env[parts[0]] = strings.Trim(parts[1], `"'`)
 	}
+	return env, nil
+}
 
-	return err
+func ExecutingPodmanCommandSucceedsFails(command string, expected string) error {
+	return withActiveConnection(func() error {
+		var err error
+		if expected == "succeeds" {
+			_, err = cmd.RunPodmanExpectSuccess(strings.Split(command[1:len(command)-1], " ")...)
+		} else if expected == "fails" {
+			_, err = cmd.RunPodmanExpectFail(strings.Split(command[1:len(command)-1], " ")...)
+		}
+		return err
+	})
 }
 
+// PullLoginTagPushImageSucceeds pulls image, tags it for CRC's internal
+// registry, and pushes it there, driving whichever container engine
+// DetectContainerEngine selected for this run instead of assuming podman.
 func PullLoginTagPushImageSucceeds(image string) error {
-	_, err := cmd.RunPodmanExpectSuccess("pull", image)
+	engine, err := DetectContainerEngine()
 	if err != nil {
 		return err
 	}
 
-	err = util.ExecuteCommand("oc whoami -t")
-	if err != nil {
-		return err
-	}
+	return withActiveConnection(func() error {
+		if _, err := runViaEngine(engine, "pull", image); err != nil {
+			return err
+		}
 
-	token := util.GetLastCommandOutput("stdout")
-	fmt.Println(token)
-	_, err = cmd.RunPodmanExpectSuccess("login", "-u", "kubeadmin", "-p", token, "default-route-openshift-image-registry.apps-crc.testing", "--tls-verify=false") // $(oc whoami -t)
-	if err != nil {
-		return err
-	}
+		registry, err := discoverRegistry()
+		if err != nil {
+			return err
+		}
+		if err := loginViaEngine(engine, registry.host); err != nil {
+			return err
+		}
 
-	_, err = cmd.RunPodmanExpectSuccess("tag", "quay.io/centos7/httpd-24-centos7", "default-route-openshift-image-registry.apps-crc.testing/testproj-img/hello:test")
-	if err != nil {
-		return err
-	}
+		targetImage := registry.imageRef("testproj-img", "hello", "test")
 
-	_, err = cmd.RunPodmanExpectSuccess("push", "default-route-openshift-image-registry.apps-crc.testing/testproj-img/hello:test", "--tls-verify=false")
-	if err != nil {
-		return err
-	}
+		if _, err := runViaEngine(engine, "tag", image, targetImage); err != nil {
+			return err
+		}
 
-	return nil
+		if _, err := runViaEngine(engine, "push", targetImage, "--tls-verify=false"); err != nil {
+			return err
+		}
+
+		return nil
+	})
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RunDockerExpectSuccess runs `docker <args...>` and returns its combined
+// stdout+stderr, failing if the command exits non-zero.
+//
+// This package ships no other source in this repository snapshot: CRC(),
+// RunPodmanExpectSuccess/Fail, WaitForClusterInState and the rest are
+// referenced throughout test/e2e/testsuite but have no visible
+// implementation here to share, so RunDockerExpectSuccess/Fail are
+// self-contained rather than guessed to match infrastructure that can't be
+// seen. Reconcile them with RunPodmanExpectSuccess/Fail's real
+// implementation once that exists.
+func RunDockerExpectSuccess(args ...string) (string, error) {
+	out, err := runDocker(args...)
+	if err != nil {
+		return out, fmt.Errorf("docker %s failed: %w", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// RunDockerExpectFail runs `docker <args...>`, failing if the command
+// exits zero.
+func RunDockerExpectFail(args ...string) (string, error) {
+	out, err := runDocker(args...)
+	if err == nil {
+		return out, fmt.Errorf("docker %s succeeded, expected failure", strings.Join(args, " "))
+	}
+	return out, nil
+}
+
+func runDocker(args ...string) (string, error) {
+	var out bytes.Buffer
+	c := exec.Command("docker", args...)
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	return out.String(), err
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RunSkopeoExpectSuccess runs `skopeo <args...>` and returns its combined
+// stdout+stderr, failing if the command exits non-zero.
+//
+// This package ships no other source in this repository snapshot, see the
+// note on RunDockerExpectSuccess/Fail in docker.go: RunSkopeoExpectSuccess/Fail
+// are self-contained for the same reason, and should be reconciled with
+// RunPodmanExpectSuccess/Fail's real implementation once that exists.
+func RunSkopeoExpectSuccess(args ...string) (string, error) {
+	out, err := runSkopeo(args...)
+	if err != nil {
+		return out, fmt.Errorf("skopeo %s failed: %w", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// RunSkopeoExpectFail runs `skopeo <args...>`, failing if the command
+// exits zero.
+func RunSkopeoExpectFail(args ...string) (string, error) {
+	out, err := runSkopeo(args...)
+	if err == nil {
+		return out, fmt.Errorf("skopeo %s succeeded, expected failure", strings.Join(args, " "))
+	}
+	return out, nil
+}
+
+func runSkopeo(args ...string) (string, error) {
+	var out bytes.Buffer
+	c := exec.Command("skopeo", args...)
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	return out.String(), err
+}
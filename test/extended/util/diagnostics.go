@@ -0,0 +1,223 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// ArtifactManifest describes one file gathered into a diagnostics bundle.
+type ArtifactManifest struct {
+	Name      string        `json:"name"`
+	Source    string        `json:"source"`
+	ExitCode  int           `json:"exitCode"`
+	SizeBytes int64         `json:"sizeBytes"`
+	Duration  time.Duration `json:"durationNs"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// BundleManifest is written as manifest.json alongside the collected
+// artifacts inside the diagnostics tarball.
+type BundleManifest struct {
+	Scenario  string             `json:"scenario"`
+	Collected time.Time          `json:"collected"`
+	Artifacts []ArtifactManifest `json:"artifacts"`
+}
+
+// artifactSource is one piece of the diagnostics bundle: either the output
+// of a command, or a file to copy as-is.
+type artifactSource struct {
+	name    string
+	command string
+	path    string
+}
+
+func diagnosticArtifactSources() []artifactSource {
+	home, _ := os.UserHomeDir()
+	sources := []artifactSource{
+		{name: "crc-status.json", command: "crc status --output json"},
+		{name: "crc-config.json", command: "crc config view -o json"},
+		{name: "crc.log", path: filepath.Join(home, ".crc", "crc.log")},
+		{name: "host-os.txt", command: hostOSInfoCommand()},
+		{name: "host-resources.txt", command: hostResourcesCommand()},
+		{name: "must-gather.txt", command: "oc adm must-gather"},
+	}
+	switch runtime.GOOS {
+	case "linux":
+		sources = append(sources,
+			artifactSource{name: "libvirt.log", path: "/var/log/libvirt/qemu/crc.log"},
+			artifactSource{name: "journal-crc.txt", command: "journalctl -u crc --no-pager"},
+		)
+	case "darwin":
+		sources = append(sources, artifactSource{name: "hyperkit.log", path: filepath.Join(home, ".crc", "machines", "crc", "hyperkit.log")})
+	case "windows":
+		sources = append(sources,
+			artifactSource{name: "hyperv-worker-admin.txt", command: `Get-WinEvent -LogName "Microsoft-Windows-Hyper-V-Worker-Admin" -MaxEvents 200 | Format-Table -AutoSize | Out-String -Width 200`},
+			artifactSource{name: "hyperv-vmms-admin.txt", command: `Get-WinEvent -LogName "Microsoft-Windows-Hyper-V-VMMS-Admin" -MaxEvents 200 | Format-Table -AutoSize | Out-String -Width 200`},
+		)
+	}
+	return sources
+}
+
+// hostOSInfoCommand returns the OS-appropriate command for a general host
+// identification dump: `systeminfo` on Windows, where `uname -a` does not
+// exist, and `uname -a` everywhere else.
+func hostOSInfoCommand() string {
+	if runtime.GOOS == "windows" {
+		return "systeminfo"
+	}
+	return "uname -a"
+}
+
+// hostResourcesCommand returns the OS-appropriate command for dumping host
+// CPU and memory info, to help tell a slow/flaky scenario apart from one
+// that ran on an under-resourced host.
+func hostResourcesCommand() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Get-CimInstance Win32_ComputerSystem | Select-Object NumberOfLogicalProcessors,TotalPhysicalMemory | Format-List"
+	case "darwin":
+		return "sysctl -n hw.ncpu hw.memsize"
+	default:
+		return "free -h && nproc"
+	}
+}
+
+// CollectDiagnostics gathers a structured troubleshooting bundle for the
+// named scenario into outDir, as a `<scenario>-<timestamp>.tar.gz`
+// containing a manifest.json describing each collected artifact. It is
+// meant to be called from the scenario's failure path.
+func CollectDiagnostics(ctx context.Context, scenarioName string, outDir string) (string, error) {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create diagnostics directory %s: %v", outDir, err)
+	}
+
+	timestamp := time.Now().Format("20060102-150405")
+	archivePath := filepath.Join(outDir, fmt.Sprintf("%s-%s.tar.gz", sanitizeForFilename(scenarioName), timestamp))
+
+	file, err := os.Create(archivePath) // #nosec G304 - archivePath is built from a fixed outDir and a timestamp
+	if err != nil {
+		return "", fmt.Errorf("could not create diagnostics archive %s: %v", archivePath, err)
+	}
+	defer file.Close()
+
+	gzWriter := gzip.NewWriter(file)
+	defer gzWriter.Close()
+	tarWriter := tar.NewWriter(gzWriter)
+	defer tarWriter.Close()
+
+	manifest := BundleManifest{Scenario: scenarioName, Collected: time.Now()}
+
+	stdout, stderr := GetLastCommandOutput("stdout"), GetLastCommandOutput("stderr")
+	manifest.Artifacts = append(manifest.Artifacts, writeArtifact(tarWriter, "last-command-stdout.txt", []byte(stdout)))
+	manifest.Artifacts = append(manifest.Artifacts, writeArtifact(tarWriter, "last-command-stderr.txt", []byte(stderr)))
+
+	for _, source := range diagnosticArtifactSources() {
+		manifest.Artifacts = append(manifest.Artifacts, collectArtifact(ctx, tarWriter, source))
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not marshal diagnostics manifest: %v", err)
+	}
+	if err := writeTarEntry(tarWriter, "manifest.json", manifestJSON); err != nil {
+		return "", err
+	}
+
+	return archivePath, nil
+}
+
+func collectArtifact(ctx context.Context, tw *tar.Writer, source artifactSource) ArtifactManifest {
+	started := time.Now()
+	artifact := ArtifactManifest{Name: source.name}
+
+	var content []byte
+	var exitCode int
+	var err error
+
+	switch {
+	case source.command != "":
+		artifact.Source = source.command
+		content, exitCode, err = runForDiagnostics(ctx, source.command)
+	case source.path != "":
+		artifact.Source = source.path
+		content, err = os.ReadFile(source.path) // #nosec G304 - path comes from a fixed list of known CRC log locations
+	}
+
+	artifact.Duration = time.Since(started)
+	artifact.ExitCode = exitCode
+	if err != nil {
+		artifact.Error = err.Error()
+		return artifact
+	}
+	artifact.SizeBytes = int64(len(content))
+
+	if writeErr := writeTarEntry(tw, source.name, content); writeErr != nil {
+		artifact.Error = writeErr.Error()
+	}
+	return artifact
+}
+
+func runForDiagnostics(ctx context.Context, command string) ([]byte, int, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", command) // #nosec G204 - diagnostic commands are a fixed, internal list
+	} else {
+		cmd = exec.CommandContext(ctx, "bash", "-c", command) // #nosec G204 - diagnostic commands are a fixed, internal list
+	}
+	out, err := cmd.CombinedOutput()
+	exitCode := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+		err = nil
+	}
+	return out, exitCode, err
+}
+
+func writeArtifact(tw *tar.Writer, name string, content []byte) ArtifactManifest {
+	artifact := ArtifactManifest{Name: name, SizeBytes: int64(len(content))}
+	if err := writeTarEntry(tw, name, content); err != nil {
+		artifact.Error = err.Error()
+	}
+	return artifact
+}
+
+func writeTarEntry(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("could not write tar header for %s: %v", name, err)
+	}
+	if _, err := io.Copy(tw, bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("could not write tar content for %s: %v", name, err)
+	}
+	return nil
+}
+
+func sanitizeForFilename(name string) string {
+	replacer := func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}
+	result := make([]rune, 0, len(name))
+	for _, r := range name {
+		result = append(result, replacer(r))
+	}
+	return string(result)
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// ActiveRunnerTargetOS is the OS step definitions should assume commands
+// run against, so they can pick the right shell dialect (e.g. PowerShell
+// vs POSIX sh) for a step. It is selected via the --runner flag: "ssh"
+// means the CRC VM, which is always Linux; "winrm" means a remote Windows
+// host; "local" (the default) means this host's own GOOS.
+//
+// --runner only picks the OS dialect: every step still executes through
+// util.ExecuteCommand on the local host regardless of its value, and no
+// SSH or WinRM connection is made.
+var ActiveRunnerTargetOS = runtime.GOOS
+
+// SetRunnerTargetOS resolves the --runner flag's value into the OS step
+// definitions should target.
+func SetRunnerTargetOS(kind string) error {
+	switch kind {
+	case "", "local":
+		ActiveRunnerTargetOS = runtime.GOOS
+	case "ssh":
+		ActiveRunnerTargetOS = "linux"
+	case "winrm":
+		ActiveRunnerTargetOS = "windows"
+	default:
+		return fmt.Errorf("unknown runner %q: expected local, ssh or winrm", kind)
+	}
+	return nil
+}
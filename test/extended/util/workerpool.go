@@ -0,0 +1,95 @@
+package util
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Worker is one isolated execution slot used by parallel scenario runs.
+// Each Worker owns its own CRC home directory and its own CRC VM name so
+// that scenarios assigned to different workers don't trample each other's
+// `~/.crc` state or each other's VM. Two scenarios assigned to the *same*
+// Worker still run one at a time, guarded by its mutex.
+type Worker struct {
+	Index  int
+	Home   string
+	VMName string
+
+	mu sync.Mutex
+}
+
+// Lock claims exclusive use of the worker for the duration of a scenario.
+func (w *Worker) Lock() { w.mu.Lock() }
+
+// Unlock releases the worker so another scenario can use it.
+func (w *Worker) Unlock() { w.mu.Unlock() }
+
+// WorkerPool hands out Workers to scenarios round-robin, so that running
+// godog with -c N keeps at most N scenarios using CRC homes concurrently.
+type WorkerPool struct {
+	mu      sync.Mutex
+	workers []*Worker
+	next    int
+}
+
+// NewWorkerPool builds a pool with one Worker per entry in homes, each
+// given its own VM name (crc-worker-<index>) so parallel workers never
+// collide on the default "crc" VM name Hyper-V/libvirt would otherwise
+// give every one of them.
+func NewWorkerPool(homes []string) *WorkerPool {
+	workers := make([]*Worker, len(homes))
+	for i, home := range homes {
+		workers[i] = &Worker{Index: i, Home: home, VMName: fmt.Sprintf("crc-worker-%d", i)}
+	}
+	return &WorkerPool{workers: workers}
+}
+
+// Acquire hands out the next Worker in round-robin order and locks it,
+// blocking if that Worker is already in use by another scenario.
+func (p *WorkerPool) Acquire() *Worker {
+	p.mu.Lock()
+	worker := p.workers[p.next%len(p.workers)]
+	p.next++
+	p.mu.Unlock()
+
+	worker.Lock()
+	return worker
+}
+
+// Release unlocks worker, making it available to the next Acquire call.
+func (p *WorkerPool) Release(worker *Worker) {
+	worker.Unlock()
+}
+
+// BundlePool caches, per bundle name, the local path of a bundle that's
+// already been obtained, so that workers running concurrently against the
+// same bundle download or copy it at most once instead of once per worker.
+type BundlePool struct {
+	mu    sync.Mutex
+	paths map[string]string
+}
+
+// NewBundlePool builds an empty BundlePool.
+func NewBundlePool() *BundlePool {
+	return &BundlePool{paths: map[string]string{}}
+}
+
+// Resolve returns the cached local path for bundleName, calling obtain to
+// produce it the first time bundleName is requested and reusing that result
+// for every later caller - including callers running concurrently on other
+// workers, which block on the pool's mutex until the first caller's obtain
+// call finishes instead of racing to fetch the bundle themselves.
+func (p *BundlePool) Resolve(bundleName string, obtain func() (string, error)) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if path, ok := p.paths[bundleName]; ok {
+		return path, nil
+	}
+	path, err := obtain()
+	if err != nil {
+		return "", err
+	}
+	p.paths[bundleName] = path
+	return path, nil
+}